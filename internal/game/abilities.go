@@ -0,0 +1,235 @@
+package game
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AbilityEffect declaration
+type AbilityEffect int
+
+// AbilityEffect enum declaration
+const (
+	DamageEffect AbilityEffect = iota
+	HealEffect
+	BlinkEffect
+	FearEffect
+)
+
+// Ability represents a spell or special ability a character can use
+type Ability struct {
+	Name     string
+	Effect   AbilityEffect
+	ManaCost int
+	Cooldown int
+	Range    int
+	Area     int
+	Power    int
+
+	// Remaining is the number of turns until the ability can be used again
+	Remaining int
+
+	knownBy []string
+}
+
+const abilitiesFile = "internal/game/data/abilities.txt"
+
+// abilityCatalog holds every ability loaded from the abilities file
+var abilityCatalog []*Ability
+
+func loadAbilities() {
+	file, err := os.Open(abilitiesFile)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 8
+	csvReader.TrimLeadingSpace = true
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	effects := map[string]AbilityEffect{
+		"damage": DamageEffect,
+		"heal":   HealEffect,
+		"blink":  BlinkEffect,
+		"fear":   FearEffect,
+	}
+
+	abilityCatalog = make([]*Ability, 0, len(rows))
+	for _, row := range rows {
+		effect, exists := effects[row[1]]
+		if !exists {
+			panic("Unknown ability effect: " + row[1])
+		}
+
+		numbers := make([]int, 5)
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(row[i+2])
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		abilityCatalog = append(abilityCatalog, &Ability{
+			Name:     row[0],
+			Effect:   effect,
+			ManaCost: numbers[0],
+			Cooldown: numbers[1],
+			Range:    numbers[2],
+			Area:     numbers[3],
+			Power:    numbers[4],
+			knownBy:  strings.Fields(row[7]),
+		})
+	}
+}
+
+// learnAbilities gives a character a copy of every ability its kind knows
+func (c *Character) learnAbilities() {
+	for _, ability := range abilityCatalog {
+		for _, name := range ability.knownBy {
+			if name == c.Name {
+				learned := *ability
+				c.Abilities = append(c.Abilities, &learned)
+			}
+		}
+	}
+}
+
+// tick advances a character's mana and cooldowns by one turn
+func (c *Character) tick() {
+	if c.Mana < c.MaxMana {
+		c.Mana++
+	}
+
+	for _, ability := range c.Abilities {
+		if ability.Remaining > 0 {
+			ability.Remaining--
+		}
+	}
+}
+
+// canCast reports whether the ability is ready and affordable
+func (c *Character) canCast(ability *Ability) bool {
+	return ability.Remaining == 0 && c.Mana >= ability.ManaCost
+}
+
+// cast uses one of the character's abilities at target, returning false if
+// nothing happened
+func (level *Level) cast(c *Character, ability *Ability, target Pos) bool {
+	if ability.Remaining > 0 {
+		level.message(ability.Name + " isn't ready yet!")
+		return false
+	}
+	if c.Mana < ability.ManaCost {
+		level.message(c.Name + " doesn't have enough mana for " + ability.Name)
+		return false
+	}
+
+	xDelta := target.X - c.X
+	yDelta := target.Y - c.Y
+	if xDelta*xDelta+yDelta*yDelta > ability.Range*ability.Range {
+		level.message("Target is out of range!")
+		return false
+	}
+
+	// ranged abilities stop at the first thing in the way
+	center := c.Pos
+	if target != c.Pos {
+		path := level.LineOfFire(c.Pos, target)
+		if len(path) == 0 {
+			level.message("No line of fire!")
+			return false
+		}
+		center = path[len(path)-1]
+	}
+
+	if ability.Effect == BlinkEffect && (center != target || !level.canWalk(target) || level.Player.Pos == target) {
+		level.message(c.Name + " can't blink there!")
+		return false
+	}
+
+	c.Mana -= ability.ManaCost
+	ability.Remaining = ability.Cooldown
+	c.ActionPoints--
+	level.addEvent(Event{Type: AbilityCast, Actor: c, From: c.Pos, Pos: center, Text: c.Name + " cast " + ability.Name})
+
+	switch ability.Effect {
+	case DamageEffect:
+		for _, victim := range level.charactersWithin(center, ability.Area) {
+			if victim != c {
+				level.damage(c, victim, ability.Power)
+			}
+		}
+	case HealEffect:
+		c.Hitpoints += ability.Power
+		if c.Hitpoints > c.MaxHitpoints {
+			c.Hitpoints = c.MaxHitpoints
+		}
+		level.addEvent(Event{Type: Healed, Actor: c, Target: c, Pos: c.Pos, Amount: ability.Power, Text: c.Name + " healed for " + strconv.Itoa(ability.Power)})
+	case BlinkEffect:
+		if monster, exists := level.Monsters[c.Pos]; exists && &monster.Character == c {
+			delete(level.Monsters, c.Pos)
+			level.Monsters[target] = monster
+		}
+		c.Pos = target
+		if c == &level.Player.Character {
+			level.lineOfSight()
+		}
+	case FearEffect:
+		for pos, monster := range level.Monsters {
+			xDelta := pos.X - center.X
+			yDelta := pos.Y - center.Y
+			if &monster.Character != c && xDelta*xDelta+yDelta*yDelta <= ability.Area*ability.Area {
+				monster.Asleep = false
+				monster.Feared = ability.Power
+				level.message(monster.Name + " is terrified!")
+			}
+		}
+	}
+
+	return true
+}
+
+// charactersWithin returns the player and monsters within area tiles of pos
+func (level *Level) charactersWithin(pos Pos, area int) []*Character {
+	characters := make([]*Character, 0)
+
+	xDelta := level.Player.X - pos.X
+	yDelta := level.Player.Y - pos.Y
+	if xDelta*xDelta+yDelta*yDelta <= area*area {
+		characters = append(characters, &level.Player.Character)
+	}
+
+	for p, monster := range level.Monsters {
+		xDelta := p.X - pos.X
+		yDelta := p.Y - pos.Y
+		if xDelta*xDelta+yDelta*yDelta <= area*area {
+			characters = append(characters, &monster.Character)
+		}
+	}
+
+	return characters
+}
+
+// damage hurts a character on behalf of an attacker, reduced by their armor
+func (level *Level) damage(attacker *Character, c *Character, amount int) {
+	if c.Armor != nil {
+		amount = int(float64(amount) * c.Armor.Power)
+	}
+
+	c.Hitpoints -= amount
+	level.recordDamage(attacker, c, amount, false, c.Name+" took "+strconv.Itoa(amount)+" damage", c.Name+" took damage", c.Name+" was killed")
+	if monster, exists := level.Monsters[c.Pos]; exists && &monster.Character == c {
+		monster.Asleep = false
+	}
+
+	level.checkDeath(c)
+}
@@ -0,0 +1,340 @@
+package game
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Dialogue files hold named nodes of text followed by the player's choices:
+//
+//	[start]
+//	Welcome, traveller!
+//	> Any work for me? -> job if !quest rats
+//	> Goodbye. -> @end
+//
+//	[job]
+//	Kill the rats and I'll pay you.
+//	> I'll do it. -> @end do quest rats
+//
+// A choice leads to another node, to "@trade" to open the merchant's wares or
+// to "@end" to finish talking. Choices are only offered when every "if"
+// condition holds, and a leading "!" negates a condition. Conditions are
+// "has <item>", "killed <monster> [count]", "visited <level>", "quest <id>",
+// "ready <id>" and "finished <id>". Picking a choice applies its "do" effects
+// in order: "give <glyph>", "gold <amount>", "open x,y", "quest <id>" and
+// "complete <id>".
+const dialogueDir = "internal/game/data/dialogue/"
+
+const (
+	startNode = "start"
+	endNode   = "@end"
+	tradeNode = "@trade"
+)
+
+// Dialogue is a branching conversation loaded from a dialogue file
+type Dialogue struct {
+	File  string
+	Nodes map[string]*DialogueNode
+}
+
+// DialogueNode is a single piece of dialogue and the replies to it
+type DialogueNode struct {
+	Name    string
+	Text    string
+	Choices []*Choice
+}
+
+// Choice is a reply the player can give in a conversation
+type Choice struct {
+	Text       string
+	Next       string
+	conditions []dialogueClause
+	effects    []dialogueClause
+}
+
+type dialogueClause struct {
+	kind   string
+	args   []string
+	negate bool
+}
+
+// Conversation is a dialogue in progress with an NPC
+type Conversation struct {
+	NPC  *NPC
+	Node *DialogueNode
+}
+
+var conditionArgs = map[string]int{
+	"has":      1,
+	"killed":   1,
+	"visited":  1,
+	"quest":    1,
+	"ready":    1,
+	"finished": 1,
+}
+
+var effectArgs = map[string]int{
+	"give":     1,
+	"gold":     1,
+	"open":     1,
+	"quest":    1,
+	"complete": 1,
+}
+
+func loadDialogue(filename string) *Dialogue {
+	file, err := os.Open(dialogueDir + filename)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	dialogue := &Dialogue{
+		File:  filename,
+		Nodes: make(map[string]*DialogueNode),
+	}
+
+	var node *DialogueNode
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := line[1 : len(line)-1]
+			node = &DialogueNode{Name: name}
+			dialogue.Nodes[name] = node
+		case node == nil:
+			panic("Dialogue outside of a node: " + line)
+		case strings.HasPrefix(line, ">"):
+			node.Choices = append(node.Choices, parseChoice(line[1:]))
+		case node.Text == "":
+			node.Text = line
+		default:
+			node.Text += " " + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+
+	if _, exists := dialogue.Nodes[startNode]; !exists {
+		panic("Dialogue has no start node: " + filename)
+	}
+	for _, node := range dialogue.Nodes {
+		for _, choice := range node.Choices {
+			if _, exists := dialogue.Nodes[choice.Next]; !exists && choice.Next != endNode && choice.Next != tradeNode {
+				panic("Unknown dialogue node: " + choice.Next)
+			}
+		}
+	}
+
+	return dialogue
+}
+
+// parseChoice parses a reply written as "text -> next [if ...] [do ...]"
+func parseChoice(line string) *Choice {
+	sep := strings.LastIndex(line, "->")
+	if sep == -1 {
+		panic("Invalid choice: " + line)
+	}
+
+	fields := strings.Fields(line[sep+2:])
+	if len(fields) == 0 {
+		panic("Invalid choice: " + line)
+	}
+
+	choice := &Choice{
+		Text: strings.TrimSpace(line[:sep]),
+		Next: fields[0],
+	}
+
+	section := ""
+	clauses := make(map[string][]string)
+	for _, field := range fields[1:] {
+		if field == "if" || field == "do" {
+			section = field
+			continue
+		}
+		if section == "" {
+			panic("Invalid choice: " + line)
+		}
+		clauses[section] = append(clauses[section], field)
+	}
+
+	choice.conditions = parseClauses(clauses["if"], conditionArgs)
+	choice.effects = parseClauses(clauses["do"], effectArgs)
+
+	return choice
+}
+
+// parseClauses splits clauses separated by ", " and checks each one is known
+func parseClauses(fields []string, known map[string]int) []dialogueClause {
+	clauses := make([]dialogueClause, 0)
+	if len(fields) == 0 {
+		return clauses
+	}
+
+	for _, text := range strings.Split(strings.Join(fields, " "), ", ") {
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			continue
+		}
+
+		clause := dialogueClause{kind: words[0], args: words[1:]}
+		if strings.HasPrefix(clause.kind, "!") {
+			clause.negate = true
+			clause.kind = clause.kind[1:]
+		}
+
+		minArgs, exists := known[clause.kind]
+		if !exists || len(clause.args) < minArgs {
+			panic("Invalid dialogue clause: " + text)
+		}
+		clauses = append(clauses, clause)
+	}
+
+	return clauses
+}
+
+// Choices returns the replies currently open to the player in the conversation
+func (level *Level) Choices() []*Choice {
+	choices := make([]*Choice, 0)
+	if level.Talking == nil {
+		return choices
+	}
+
+	for _, choice := range level.Talking.Node.Choices {
+		if level.checkConditions(choice.conditions) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices
+}
+
+func (level *Level) checkConditions(conditions []dialogueClause) bool {
+	for _, condition := range conditions {
+		if level.checkCondition(condition) == condition.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (level *Level) checkCondition(condition dialogueClause) bool {
+	journal := level.Journal
+	if journal == nil {
+		journal = newJournal()
+	}
+	arg := condition.args[0]
+
+	switch condition.kind {
+	case "has":
+		name := strings.Join(condition.args, " ")
+		for _, item := range level.Player.Items {
+			if item.Name == name {
+				return true
+			}
+		}
+		return false
+	case "killed":
+		count := 1
+		if len(condition.args) > 1 {
+			n, err := strconv.Atoi(condition.args[1])
+			if err != nil {
+				panic(err)
+			}
+			count = n
+		}
+		return journal.Kills[arg] >= count
+	case "visited":
+		return journal.Visited[arg]
+	case "quest":
+		return journal.Quest(arg) != nil
+	case "ready":
+		quest := journal.Quest(arg)
+		return quest != nil && quest.State == QuestReady
+	case "finished":
+		quest := journal.Quest(arg)
+		return quest != nil && quest.State == QuestFinished
+	}
+
+	return false
+}
+
+func (level *Level) applyEffect(effect dialogueClause) {
+	player := &level.Player.Character
+	arg := effect.args[0]
+
+	switch effect.kind {
+	case "give":
+		glyph := []rune(arg)
+		newItem, exists := itemCatalog[glyph[0]]
+		if !exists {
+			panic("Invalid Character: " + arg)
+		}
+		item := newItem(player.Pos)
+		if !player.addItem(item) {
+			// drop what doesn't fit at the player's feet
+			level.Items[player.Pos] = stackItems(level.Items[player.Pos], item)
+		}
+		level.addEvent(Event{Type: ItemPickedUp, Actor: player, Item: item, Pos: player.Pos, Text: player.Name + " received " + item.Name})
+	case "gold":
+		amount, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(err)
+		}
+		player.Gold += amount
+		level.message(player.Name + " received " + arg + " gold")
+	case "open":
+		pos := parsePos(arg)
+		if !level.inRange(pos) {
+			return
+		}
+		switch level.Tiles[pos.Y][pos.X].OverlaySymbol {
+		case ClosedDoorTile, LockedDoorTile, SecretDoorTile:
+			delete(level.Locks, pos)
+			level.Tiles[pos.Y][pos.X].OverlaySymbol = OpenedDoorTile
+			level.addEvent(Event{Type: DoorOpened, Pos: pos, Text: "A door creaks open"})
+			level.lineOfSight()
+		}
+	case "quest":
+		level.startQuest(arg)
+	case "complete":
+		level.finishQuest(arg)
+	}
+}
+
+// choose picks one of the replies open to the player in the conversation
+func (level *Level) choose(index int) {
+	conversation := level.Talking
+	if conversation == nil {
+		return
+	}
+
+	choices := level.Choices()
+	if index < 0 || index >= len(choices) {
+		level.Talking = nil
+		return
+	}
+
+	choice := choices[index]
+	for _, effect := range choice.effects {
+		level.applyEffect(effect)
+	}
+
+	switch choice.Next {
+	case endNode:
+		level.Talking = nil
+	case tradeNode:
+		level.Talking = nil
+		level.Trading = conversation.NPC
+	default:
+		conversation.Node = conversation.NPC.Dialogue.Nodes[choice.Next]
+	}
+}
@@ -0,0 +1,223 @@
+package game
+
+import (
+	"os"
+	"sort"
+)
+
+// Cell represents everything placed on a single tile of a level
+type Cell struct {
+	Tile    Tile
+	Monster *Monster
+	Items   []*Item
+	Portal  *LevelPos
+	Lock    string
+	Trap    *Trap
+	NPC     *NPC
+}
+
+// InRange reports whether pos lies within the level
+func (level *Level) InRange(pos Pos) bool {
+	return level.inRange(pos)
+}
+
+// Cell returns a copy of the contents of the tile at pos
+func (level *Level) Cell(pos Pos) Cell {
+	items := make([]*Item, len(level.Items[pos]))
+	copy(items, level.Items[pos])
+
+	return Cell{
+		Tile:    level.Tiles[pos.Y][pos.X],
+		Monster: level.Monsters[pos],
+		Items:   items,
+		Portal:  level.Portals[pos],
+		Lock:    level.Locks[pos],
+		Trap:    level.Traps[pos],
+		NPC:     level.NPCs[pos],
+	}
+}
+
+// SetCell replaces the contents of the tile at pos
+func (level *Level) SetCell(pos Pos, cell Cell) {
+	level.Tiles[pos.Y][pos.X] = cell.Tile
+
+	delete(level.Monsters, pos)
+	if cell.Monster != nil {
+		cell.Monster.Pos = pos
+		level.Monsters[pos] = cell.Monster
+	}
+
+	delete(level.Items, pos)
+	if len(cell.Items) > 0 {
+		items := make([]*Item, len(cell.Items))
+		copy(items, cell.Items)
+		level.Items[pos] = items
+	}
+
+	delete(level.Portals, pos)
+	if cell.Portal != nil {
+		level.Portals[pos] = cell.Portal
+	}
+
+	delete(level.Locks, pos)
+	if cell.Lock != "" {
+		level.Locks[pos] = cell.Lock
+	}
+
+	delete(level.Traps, pos)
+	if cell.Trap != nil {
+		level.Traps[pos] = cell.Trap
+	}
+
+	delete(level.NPCs, pos)
+	if cell.NPC != nil {
+		cell.NPC.Pos = pos
+		level.NPCs[pos] = cell.NPC
+	}
+}
+
+// Paint places a tile, overlay, monster or item glyph at pos, clearing
+// anything that can no longer stand on the tile. Returns false if the glyph
+// can't be placed there.
+func (level *Level) Paint(pos Pos, glyph rune) bool {
+	if !level.inRange(pos) {
+		return false
+	}
+
+	tile := &level.Tiles[pos.Y][pos.X]
+
+	switch glyph {
+	case StoneTile, EmptyTile, ' ':
+		if level.Player != nil && level.Player.Pos == pos {
+			return false
+		}
+		level.Erase(pos)
+		tile.Symbol = StoneTile
+		if glyph != StoneTile {
+			tile.Symbol = EmptyTile
+		}
+		return true
+	case DirtTile:
+		tile.Symbol = DirtTile
+		return true
+	case '@':
+		return false
+	}
+
+	if tile.Symbol != DirtTile {
+		tile.Symbol = DirtTile
+	}
+
+	if level.Player != nil && level.Player.Pos == pos {
+		switch glyph {
+		case 'R', 'S':
+			return false
+		}
+	}
+
+	switch glyph {
+	case 'R', 'S':
+		if _, exists := level.NPCs[pos]; exists {
+			return false
+		}
+		delete(level.Monsters, pos)
+	case ClosedDoorTile, OpenedDoorTile, LockedDoorTile, SecretDoorTile, UpStairTile, DownStairTile:
+		tile.OverlaySymbol = EmptyTile
+		delete(level.Locks, pos)
+		delete(level.Traps, pos)
+	case SpikeTrapTile, GasTrapTile, TeleportTrapTile, AlarmTrapTile:
+		tile.OverlaySymbol = EmptyTile
+		delete(level.Locks, pos)
+	}
+
+	return level.placeGlyph(glyph, pos)
+}
+
+// Erase removes overlays, monsters, items and portals from the tile at pos
+func (level *Level) Erase(pos Pos) {
+	if !level.inRange(pos) {
+		return
+	}
+
+	level.Tiles[pos.Y][pos.X].OverlaySymbol = EmptyTile
+	delete(level.Monsters, pos)
+	delete(level.Items, pos)
+	delete(level.Portals, pos)
+	delete(level.Locks, pos)
+	delete(level.Traps, pos)
+	delete(level.NPCs, pos)
+}
+
+// LevelNames returns the names of all loaded levels in sorted order
+func (game *Game) LevelNames() []string {
+	names := make([]string, 0, len(game.Levels))
+	for name := range game.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LevelName returns the name a level was loaded under
+func (game *Game) LevelName(level *Level) string {
+	for name, l := range game.Levels {
+		if l == level {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// SetPortal places a portal at pos leading to the start of the target level
+func (game *Game) SetPortal(level *Level, pos Pos, target string) bool {
+	targetLevel := game.Levels[target]
+	if targetLevel == nil || targetLevel == level || !level.inRange(pos) {
+		return false
+	}
+
+	targetPos, exists := targetLevel.Spawns["start"]
+	if !exists && targetLevel.Player != nil {
+		targetPos = targetLevel.Player.Pos
+	}
+
+	level.Tiles[pos.Y][pos.X].Symbol = DirtTile
+	level.Portals[pos] = &LevelPos{Level: targetLevel, Pos: targetPos}
+
+	return true
+}
+
+// EditLevel loads the named level afresh from its map file for the editor, so
+// nothing that happened in play can be saved. Portals belong to the world file
+// rather than the map, so the copy shares them with the level being played.
+func (game *Game) EditLevel(name string) *Level {
+	level := loadLevel(name)
+	if playing, exists := game.Levels[name]; exists {
+		level.Portals = playing.Portals
+	}
+
+	return level
+}
+
+// SaveLevel writes a level back to the named map file
+func (game *Game) SaveLevel(name string, level *Level) error {
+	file, err := os.Create(mapsDir + name + ".map")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return level.writeMap(file)
+}
+
+// SaveWorld writes the starting level and every portal to the world file
+func (game *Game) SaveWorld() error {
+	file, err := os.Create(worldFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return game.writeWorld(file)
+}
@@ -0,0 +1,100 @@
+package game
+
+// EventType is the kind of thing that happened during a turn
+type EventType int
+
+// Enum of game events
+const (
+	Message EventType = iota
+	Moved
+	DoorOpened
+	DoorClosed
+	Attacked
+	Damaged
+	Killed
+	Healed
+	AbilityCast
+	ItemPickedUp
+	ItemDropped
+	TrapTriggered
+	Discovered
+	Talked
+	Traded
+	QuestUpdated
+	LevelChanged
+)
+
+// Event is something that happened during a turn. Actor is whoever did it
+// and Target whoever it was done to, either may be nil. From is where the
+// actor was and Pos where it happened. Text is the line shown in the message
+// log, events with no text aren't logged. Summary is the line without the
+// details that change each time, shown when the event repeats.
+type Event struct {
+	Type     EventType
+	Actor    *Character
+	Target   *Character
+	Item     *Item
+	From     Pos
+	Pos      Pos
+	Amount   int
+	Critical bool
+	Text     string
+	Summary  string
+}
+
+// addEvent records something that happened this turn, logging its text
+func (level *Level) addEvent(event Event) {
+	level.Events = append(level.Events, event)
+	if event.Text != "" && level.Log != nil {
+		level.Log.add(event)
+	}
+}
+
+// message records a line for the message log that has no other meaning
+func (level *Level) message(text string) {
+	level.addEvent(Event{Type: Message, Text: text})
+}
+
+// recordDamage records a character taking damage, and dying if it did. Only
+// the kill is logged when the damage was fatal.
+func (level *Level) recordDamage(attacker *Character, defender *Character, damage int, critical bool, text string, summary string, killText string) {
+	event := Event{
+		Type:     Damaged,
+		Actor:    attacker,
+		Target:   defender,
+		From:     defender.Pos,
+		Pos:      defender.Pos,
+		Amount:   damage,
+		Critical: critical,
+		Text:     text,
+		Summary:  summary,
+	}
+	if attacker != nil {
+		event.From = attacker.Pos
+	}
+
+	if defender.Hitpoints > 0 {
+		level.addEvent(event)
+		return
+	}
+
+	event.Text = ""
+	level.addEvent(event)
+
+	event.Type = Killed
+	event.Text = killText
+	event.Summary = ""
+	level.addEvent(event)
+}
+
+// recordAttack records a character striking another, before the damage is
+// worked out
+func (level *Level) recordAttack(attacker *Character, defender *Character) {
+	level.addEvent(Event{
+		Type:   Attacked,
+		Actor:  attacker,
+		Target: defender,
+		From:   attacker.Pos,
+		Pos:    defender.Pos,
+	})
+}
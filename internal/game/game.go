@@ -3,7 +3,9 @@ package game
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strconv"
 )
 
@@ -20,14 +22,26 @@ const (
 	TakeItem
 	DropItem
 	EquipItem
+	UseItem
 	TakeAll
+	CloseDoor
+	Search
+	Fire
+	CastAbility
+	Buy
+	Sell
+	EndTrade
+	Choose
 	None
 )
 
 // Input represents the key board input (Tagged Union / DU)
 type Input struct {
-	Type InputType
-	Item *Item
+	Type    InputType
+	Item    *Item
+	Target  Pos
+	Ability int
+	Choice  int
 }
 
 // Pos reprsents the x an y coordinate
@@ -35,6 +49,28 @@ type Pos struct {
 	X, Y int
 }
 
+// adjacent returns the positions to the right, left, above and below p, plus
+// the diagonals if requested
+func (p Pos) adjacent(diagonals bool) []Pos {
+	positions := []Pos{
+		{X: p.X + 1, Y: p.Y},
+		{X: p.X - 1, Y: p.Y},
+		{X: p.X, Y: p.Y - 1},
+		{X: p.X, Y: p.Y + 1},
+	}
+
+	if diagonals {
+		positions = append(positions,
+			Pos{X: p.X + 1, Y: p.Y - 1},
+			Pos{X: p.X + 1, Y: p.Y + 1},
+			Pos{X: p.X - 1, Y: p.Y - 1},
+			Pos{X: p.X - 1, Y: p.Y + 1},
+		)
+	}
+
+	return positions
+}
+
 // Entity represents the identity of game entity (ie. player, monsters, items)
 type Entity struct {
 	Pos
@@ -46,46 +82,72 @@ type Entity struct {
 type Character struct {
 	Entity
 	Hitpoints    int
+	MaxHitpoints int
+	Mana         int
+	MaxMana      int
+	Gold         int
 	Damage       int
 	Speed        float64
 	ActionPoints float64
 	SightRange   int
+	Perception   int
+	MaxSlots     int
+	MaxWeight    float64
 	Weapon       *Item
 	Armor        *Item
 	Items        []*Item
+	Abilities    []*Ability
 }
 
-// Game represents the RPG game state
+// Game represents the RPG game state. The levels are handed back and forth
+// over the channels: the game owns them from receiving an input until it sends
+// the current level back, and the ui owns them the rest of the time. Only one
+// input is sent per level handed back, so with room for one value in each
+// channel neither side ever blocks sending.
 type Game struct {
 	LevelCh      chan *Level
 	InputCh      chan *Input
 	Levels       map[string]*Level
 	CurrentLevel *Level
+
+	startLevel string
 }
 
 // NewGame creates a new Game struct
 func NewGame(path string) *Game {
 
+	// load abilities before any characters are created
+	loadAbilities()
+	loadQuests()
+
 	// load levels from maps directory
 	levels := loadLevels()
 
-	// set initial level
-	currentLevel := levels["level1"]
-	currentLevel.lineOfSight()
-
 	game := &Game{
-		LevelCh:      make(chan *Level),
-		InputCh:      make(chan *Input),
-		Levels:       levels,
-		CurrentLevel: currentLevel,
+		LevelCh: make(chan *Level, 1),
+		InputCh: make(chan *Input, 1),
+		Levels:  levels,
 	}
 
+	// the world file names the starting level
 	game.loadWorld()
+	game.CurrentLevel.lineOfSight()
+
+	// every level shares the player's journal and message history
+	journal := newJournal()
+	log := newMessageLog()
+	for _, level := range levels {
+		level.Journal = journal
+		level.Log = log
+	}
+	game.CurrentLevel.recordVisit(game.startLevel)
 
 	return game
 }
 
-// Run runs the game user interface
+// Run runs the game user interface. It hands over the starting level, then
+// for every input takes a turn and hands the current level back. Nothing else
+// may touch a level between sending an input and receiving the level.
 func (game *Game) Run() {
 	game.LevelCh <- game.CurrentLevel
 
@@ -102,6 +164,10 @@ func (game *Game) Run() {
 
 func (game *Game) handleInput(input *Input) {
 	level := game.CurrentLevel
+	level.Events = nil
+	if level.Log != nil && input.Type != None {
+		level.Log.Turn++
+	}
 	var pos Pos
 	newPos := false
 
@@ -124,15 +190,45 @@ func (game *Game) handleInput(input *Input) {
 		level.dropItem(input.Item, &level.Player.Character)
 	case EquipItem:
 		level.equip(&level.Player.Character, input.Item)
+	case UseItem:
+		if level.useItem(&level.Player.Character, input.Item) {
+			level.updateMonsters()
+		}
 	case TakeAll:
-		items := level.Items[level.Player.Pos]
+		items := make([]*Item, len(level.Items[level.Player.Pos]))
+		copy(items, level.Items[level.Player.Pos])
 		if len(items) > 0 {
 			for _, item := range items {
+				// keep going when one doesn't fit, gold and stacks may still go in
 				level.moveItem(item, &level.Player.Character)
-				level.AddEvent("Player picked up " + item.Name)
 			}
 		} else {
-			level.AddEvent("Nothing to take!")
+			level.message("Nothing to take!")
+		}
+	case CloseDoor:
+		level.closeDoor()
+		level.updateMonsters()
+	case Search:
+		level.search()
+		level.updateMonsters()
+	case Fire:
+		if level.fire(&level.Player.Character, input.Target) {
+			level.updateMonsters()
+		}
+	case Buy:
+		level.buy(input.Item)
+	case Sell:
+		level.sell(input.Item)
+	case EndTrade:
+		level.endTrade()
+	case Choose:
+		level.choose(input.Choice)
+	case CastAbility:
+		abilities := level.Player.Abilities
+		if input.Ability >= 0 && input.Ability < len(abilities) {
+			if level.cast(&level.Player.Character, abilities[input.Ability], input.Target) {
+				level.updateMonsters()
+			}
 		}
 	default:
 		// do nothing
@@ -143,16 +239,30 @@ func (game *Game) handleInput(input *Input) {
 		// check if at portal
 		nextLevel := level.Portals[pos]
 		if nextLevel != nil {
-			level.LastEvent = Portal
+			from := level.Player.Pos
 			level.Player.Pos = nextLevel.Pos
 			game.CurrentLevel = nextLevel.Level
+			game.CurrentLevel.Events = nil
+			game.CurrentLevel.addEvent(Event{Type: LevelChanged, Actor: &level.Player.Character, From: from, Pos: nextLevel.Pos})
 			game.CurrentLevel.lineOfSight()
+			game.CurrentLevel.recordVisit(game.LevelName(game.CurrentLevel))
 		}
 
 		level.resolveMove(pos)
 	}
+
+	// events on the level the player left are about its characters and
+	// positions, the ui only sees those of the level the player ends up on
+	if game.CurrentLevel != level {
+		level.Events = nil
+	}
 }
 
+const (
+	mapsDir   = "internal/game/maps/"
+	worldFile = mapsDir + "world.txt"
+)
+
 // LevelPos represents the starting location of a level
 type LevelPos struct {
 	Level *Level
@@ -160,7 +270,7 @@ type LevelPos struct {
 }
 
 func (game *Game) loadWorld() {
-	file, err := os.Open("internal/game/maps/world.txt")
+	file, err := os.Open(worldFile)
 	if err != nil {
 		panic(err)
 	}
@@ -177,41 +287,107 @@ func (game *Game) loadWorld() {
 	for rowIdx, row := range rows {
 		if rowIdx == 0 {
 			game.CurrentLevel = game.Levels[row[0]]
+			game.startLevel = row[0]
 			continue
 		}
 
+		// rows are either "level,spawn,level,spawn" or "level,x,y,level,x,y"
+		if len(row) != 4 && len(row) != 6 {
+			fmt.Println("Invalid portal in the world file")
+			panic(nil)
+		}
+		half := len(row) / 2
+
 		levelWithPortal := game.Levels[row[0]]
 		if levelWithPortal == nil {
 			fmt.Println("Couldn't find level name in the world file")
 			panic(nil)
 		}
 
-		x, err := strconv.ParseInt(row[1], 10, 64)
-		if err != nil {
-			panic(err)
-		}
-		y, err := strconv.ParseInt(row[2], 10, 64)
-		if err != nil {
-			panic(err)
-		}
-		pos := Pos{X: int(x), Y: int(y)}
-
-		levelToGo := game.Levels[row[3]]
+		levelToGo := game.Levels[row[half]]
 		if levelToGo == nil {
 			fmt.Println("Couldn't find level name in the world file")
 			panic(nil)
 		}
 
-		x, err = strconv.ParseInt(row[4], 10, 64)
-		if err != nil {
-			panic(err)
+		pos := worldPos(levelWithPortal, row[1:half])
+		posToGo := worldPos(levelToGo, row[half+1:])
+
+		levelWithPortal.Portals[pos] = &LevelPos{Level: levelToGo, Pos: posToGo}
+	}
+}
+
+// worldPos resolves a portal location from the world file, given either as a
+// spawn point name or as x and y coordinates
+func worldPos(level *Level, fields []string) Pos {
+	if len(fields) == 1 {
+		pos, exists := level.Spawns[fields[0]]
+		if !exists {
+			fmt.Println("Couldn't find spawn point " + fields[0] + " in the world file")
+			panic(nil)
 		}
-		y, err = strconv.ParseInt(row[5], 10, 64)
-		if err != nil {
-			panic(err)
+		return pos
+	}
+
+	x, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	y, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		panic(err)
+	}
+
+	return Pos{X: int(x), Y: int(y)}
+}
+
+func (game *Game) writeWorld(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+
+	err := csvWriter.Write([]string{game.startLevel})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range game.LevelNames() {
+		level := game.Levels[name]
+
+		portals := make([]Pos, 0, len(level.Portals))
+		for pos := range level.Portals {
+			portals = append(portals, pos)
 		}
-		posToGo := Pos{X: int(x), Y: int(y)}
+		sort.Slice(portals, func(i, j int) bool {
+			if portals[i].Y != portals[j].Y {
+				return portals[i].Y < portals[j].Y
+			}
+			return portals[i].X < portals[j].X
+		})
 
-		levelWithPortal.Portals[pos] = &LevelPos{Level: levelToGo, Pos: posToGo}
+		for _, pos := range portals {
+			portal := level.Portals[pos]
+			target := game.LevelName(portal.Level)
+
+			// prefer spawn names, falling back to coordinates
+			spawn := level.spawnAt(pos)
+			targetSpawn := portal.Level.spawnAt(portal.Pos)
+
+			var row []string
+			if spawn != "" && targetSpawn != "" {
+				row = []string{name, spawn, target, targetSpawn}
+			} else {
+				row = []string{
+					name, strconv.Itoa(pos.X), strconv.Itoa(pos.Y),
+					target, strconv.Itoa(portal.Pos.X), strconv.Itoa(portal.Pos.Y),
+				}
+			}
+
+			err = csvWriter.Write(row)
+			if err != nil {
+				return err
+			}
+		}
 	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
 }
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// turnTimeout is how long a test waits for the game to hand a level back
+const turnTimeout = 5 * time.Second
+
+func TestMain(m *testing.M) {
+	// the game loads its maps and data relative to the repository root
+	err := os.Chdir("../..")
+	if err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// frontend stands in for the ui, handing inputs to a running game and taking
+// the level back over the channels the way the ui does
+type frontend struct {
+	t    *testing.T
+	game *Game
+	done chan struct{}
+}
+
+// startGame creates a game with a player that can't die, runs it and takes
+// the starting level
+func startGame(t *testing.T) (*frontend, *Level) {
+	game := NewGame("")
+	for _, level := range game.Levels {
+		level.Player.Hitpoints = 1 << 20
+	}
+
+	f := &frontend{t: t, game: game, done: make(chan struct{})}
+	go func() {
+		game.Run()
+		close(f.done)
+	}()
+
+	return f, f.receive()
+}
+
+// send hands the game an input, failing if it would have to wait
+func (f *frontend) send(input *Input) {
+	select {
+	case f.game.InputCh <- input:
+	default:
+		f.t.Fatal("sending an input blocked")
+	}
+}
+
+// receive takes the level back from the game
+func (f *frontend) receive() *Level {
+	select {
+	case level := <-f.game.LevelCh:
+		return level
+	case <-time.After(turnTimeout):
+		f.t.Fatal("the game didn't hand the level back")
+		return nil
+	}
+}
+
+// quit stops the game and waits for Run to return
+func (f *frontend) quit() {
+	f.send(&Input{Type: QuitGame})
+
+	select {
+	case <-f.done:
+	case <-time.After(turnTimeout):
+		f.t.Fatal("the game didn't stop")
+	}
+}
+
+func TestRunHandsLevelBack(t *testing.T) {
+	f, level := startGame(t)
+	if level != f.game.CurrentLevel {
+		t.Fatal("the game didn't hand over the current level")
+	}
+
+	inputs := []InputType{Up, Right, Down, Left, Search, CloseDoor, TakeAll}
+	for i, inputType := range inputs {
+		turn := level.Log.Turn
+		f.send(&Input{Type: inputType})
+		level = f.receive()
+
+		// the frontend owns the level again, touching it here is caught by
+		// the race detector if the game still is too
+		if level.Log.Turn != turn+1 {
+			t.Fatalf("input %d took the log from turn %d to %d", i, turn, level.Log.Turn)
+		}
+		level.Debug = make(map[Pos]bool)
+		for _, event := range level.Events {
+			if event.Type == LevelChanged {
+				t.Fatalf("input %d changed level", i)
+			}
+		}
+	}
+
+	f.quit()
+}
+
+func TestOneLevelPerInput(t *testing.T) {
+	f, level := startGame(t)
+
+	// nothing more comes back until an input is sent
+	select {
+	case <-f.game.LevelCh:
+		t.Fatal("the game handed a level back without an input")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for i := 0; i < 10; i++ {
+		f.send(&Input{Type: Search})
+
+		// the game sends the level back without waiting for it to be taken
+		deadline := time.Now().Add(turnTimeout)
+		for len(f.game.LevelCh) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("the game didn't hand the level back")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		level = f.receive()
+
+		select {
+		case <-f.game.LevelCh:
+			t.Fatalf("input %d handed back more than one level", i)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		level.Player.Hitpoints = 1 << 20
+	}
+
+	f.quit()
+}
@@ -0,0 +1,162 @@
+package game
+
+import "strconv"
+
+// Encumbered characters move at this fraction of their normal speed
+const encumberedSpeed = 0.5
+
+// Weight returns the total weight of everything the character carries,
+// including equipped items
+func (c *Character) Weight() float64 {
+	weight := 0.0
+	for _, item := range c.Items {
+		weight += item.Weight * float64(item.Count)
+	}
+	if c.Weapon != nil {
+		weight += c.Weapon.Weight * float64(c.Weapon.Count)
+	}
+	if c.Armor != nil {
+		weight += c.Armor.Weight * float64(c.Armor.Count)
+	}
+
+	return weight
+}
+
+// Encumbered reports whether the character carries more than they can
+// comfortably manage
+func (c *Character) Encumbered() bool {
+	return c.MaxWeight > 0 && c.Weight() > c.MaxWeight
+}
+
+// findStack returns the carried stack an item can be merged into, or nil
+func (c *Character) findStack(item *Item) *Item {
+	if !item.Stackable {
+		return nil
+	}
+
+	for _, carried := range c.Items {
+		if carried != item && carried.Stackable && carried.Name == item.Name && carried.Type == item.Type {
+			return carried
+		}
+	}
+
+	return nil
+}
+
+// canCarry reports whether the character has a free slot for an item
+func (c *Character) canCarry(item *Item) bool {
+	if c.MaxSlots == 0 || c.findStack(item) != nil {
+		return true
+	}
+
+	return len(c.Items) < c.MaxSlots
+}
+
+// addItem puts an item in the character's pack, merging it into a matching
+// stack. Returns false if there's no room for it.
+func (c *Character) addItem(item *Item) bool {
+	if item.Type == Gold {
+		c.Gold += item.Value * item.Count
+		return true
+	}
+
+	if !c.canCarry(item) {
+		return false
+	}
+
+	if stack := c.findStack(item); stack != nil {
+		stack.Count += item.Count
+		return true
+	}
+
+	c.Items = append(c.Items, item)
+	return true
+}
+
+// stackItems adds an item to a pile on the floor, merging it into a matching
+// stack
+func stackItems(items []*Item, item *Item) []*Item {
+	if item.Stackable {
+		for _, pile := range items {
+			if pile.Stackable && pile.Name == item.Name && pile.Type == item.Type {
+				pile.Count += item.Count
+				return items
+			}
+		}
+	}
+
+	return append(items, item)
+}
+
+// checkEncumbrance slows the player down while they carry too much
+func (level *Level) checkEncumbrance() {
+	player := level.Player
+
+	speed := playerSpeed
+	if player.Encumbered() {
+		speed = playerSpeed * encumberedSpeed
+	}
+
+	if speed < player.Speed {
+		level.message(player.Name + " is encumbered")
+	} else if speed > player.Speed {
+		level.message(player.Name + " is no longer encumbered")
+	}
+	player.Speed = speed
+}
+
+// removeOne takes a single item off a carried stack, removing the item once
+// the stack is empty
+func (c *Character) removeOne(targetItem *Item) {
+	for i, item := range c.Items {
+		if item != targetItem {
+			continue
+		}
+
+		item.Count--
+		if item.Count <= 0 {
+			c.Items = append(c.Items[:i], c.Items[i+1:]...)
+		}
+		return
+	}
+}
+
+// useItem drinks, applies or equips a carried item, returning true if it took
+// the character's turn
+func (level *Level) useItem(c *Character, item *Item) bool {
+	switch item.Type {
+	case Potion:
+		healed := int(item.Power)
+		if c.Hitpoints+healed > c.MaxHitpoints {
+			healed = c.MaxHitpoints - c.Hitpoints
+		}
+		c.Hitpoints += healed
+		c.removeOne(item)
+		level.addEvent(Event{
+			Type:    Healed,
+			Actor:   c,
+			Target:  c,
+			Item:    item,
+			Pos:     c.Pos,
+			Amount:  healed,
+			Text:    c.Name + " drank a " + item.Name + " and healed " + strconv.Itoa(healed),
+			Summary: c.Name + " drank a " + item.Name,
+		})
+		return true
+	case Key:
+		for _, pos := range c.Pos.adjacent(true) {
+			if level.Locks[pos] == item.KeyID && level.inRange(pos) &&
+				level.Tiles[pos.Y][pos.X].OverlaySymbol == LockedDoorTile {
+				level.unlockDoor(pos)
+				return true
+			}
+		}
+		level.message("There's no door here for the " + item.Name)
+	case Weapon, Armor:
+		level.equip(c, item)
+	default:
+		level.message(c.Name + " can't use the " + item.Name)
+	}
+
+	return false
+}
@@ -1,10 +1,21 @@
 package game
 
+import "strings"
+
 // Item struct declaration
 type Item struct {
 	Entity
-	Type  ItemType
-	Power float64
+	Type      ItemType
+	Power     float64
+	Value     int
+	Weight    float64
+	Count     int
+	Stackable bool
+	KeyID     string
+	Range     int
+	AmmoName  string
+	Charges   int
+	Thrown    bool
 }
 
 // ItemType declaration
@@ -14,9 +25,26 @@ type ItemType int
 const (
 	Weapon ItemType = iota
 	Armor
+	Key
+	Ammo
+	Gold
+	Potion
 	Other
 )
 
+// itemCatalog maps item glyphs to the functions that create them
+var itemCatalog = map[rune]func(Pos) *Item{
+	's': NewSword,
+	'h': NewHelmet,
+	'k': func(p Pos) *Item { return NewKey(p, "") },
+	'b': NewBow,
+	'a': NewArrows,
+	't': NewDagger,
+	'w': NewWand,
+	'$': NewGold,
+	'p': NewPotion,
+}
+
 // NewSword creates a sword entity
 func NewSword(p Pos) *Item {
 	return &Item{
@@ -25,8 +53,11 @@ func NewSword(p Pos) *Item {
 			Name:   "Sword",
 			Symbol: 's',
 		},
-		Type:  Weapon,
-		Power: 2.0,
+		Type:   Weapon,
+		Value:  20,
+		Power:  2.0,
+		Weight: 3.0,
+		Count:  1,
 	}
 }
 
@@ -38,7 +69,159 @@ func NewHelmet(p Pos) *Item {
 			Name:   "Helmet",
 			Symbol: 'h',
 		},
-		Type:  Armor,
-		Power: 0.8,
+		Type:   Armor,
+		Value:  15,
+		Power:  0.8,
+		Weight: 2.0,
+		Count:  1,
+	}
+}
+
+// NewKey creates a key entity that opens doors locked with the same id
+func NewKey(p Pos, id string) *Item {
+	name := "Key"
+	if id != "" {
+		name = strings.ToUpper(id[:1]) + id[1:] + " Key"
+	}
+
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   name,
+			Symbol: 'k',
+		},
+		Type:   Key,
+		KeyID:  id,
+		Weight: 0.1,
+		Count:  1,
+	}
+}
+
+// NewBow creates a bow entity that fires arrows
+func NewBow(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Bow",
+			Symbol: 'b',
+		},
+		Type:     Weapon,
+		Value:    25,
+		Power:    1.5,
+		Range:    8,
+		AmmoName: "Arrows",
+		Weight:   2.0,
+		Count:    1,
+	}
+}
+
+// NewArrows creates a bundle of arrows entity
+func NewArrows(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Arrows",
+			Symbol: 'a',
+		},
+		Type:      Ammo,
+		Value:     1,
+		Weight:    0.1,
+		Count:     10,
+		Stackable: true,
+	}
+}
+
+// NewDagger creates a dagger entity that can be thrown
+func NewDagger(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Dagger",
+			Symbol: 't',
+		},
+		Type:   Weapon,
+		Value:  10,
+		Power:  1.5,
+		Range:  5,
+		Thrown: true,
+		Weight: 1.0,
+		Count:  1,
+	}
+}
+
+// NewWand creates a wand entity with a limited number of charges
+func NewWand(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Wand",
+			Symbol: 'w',
+		},
+		Type:    Weapon,
+		Value:   40,
+		Power:   2.0,
+		Range:   6,
+		Charges: 5,
+		Weight:  0.5,
+		Count:   1,
+	}
+}
+
+// NewGold creates a pile of gold entity
+func NewGold(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Gold",
+			Symbol: '$',
+		},
+		Type:      Gold,
+		Value:     1,
+		Count:     10,
+		Stackable: true,
 	}
 }
+
+// NewPotion creates a healing potion entity
+func NewPotion(p Pos) *Item {
+	return &Item{
+		Entity: Entity{
+			Pos:    p,
+			Name:   "Potion",
+			Symbol: 'p',
+		},
+		Type:      Potion,
+		Value:     15,
+		Power:     10,
+		Weight:    0.5,
+		Count:     1,
+		Stackable: true,
+	}
+}
+
+// Price returns what a merchant charges for an item or stack, based on its
+// value and how powerful it is
+func (item *Item) Price() int {
+	rating := 1.0
+	switch item.Type {
+	case Weapon:
+		rating = item.Power
+	case Armor:
+		// armor power scales damage taken, so lower is better
+		if item.Power > 0 {
+			rating = 1 / item.Power
+		}
+	}
+
+	price := int(float64(item.Value) * rating)
+	if price < 1 && item.Value > 0 {
+		price = 1
+	}
+
+	return price * item.Count
+}
+
+// SellPrice returns what a merchant pays for an item
+func (item *Item) SellPrice() int {
+	return item.Price() / 2
+}
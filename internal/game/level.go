@@ -3,6 +3,7 @@ package game
 import (
 	"bufio"
 	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -23,6 +24,8 @@ const (
 	DirtTile            = '.'
 	ClosedDoorTile      = '|'
 	OpenedDoorTile      = '/'
+	LockedDoorTile      = '+'
+	SecretDoorTile      = '%'
 	EmptyTile           = 0
 	PlayerTile          = '@'
 	UpStairTile         = 'u'
@@ -30,35 +33,32 @@ const (
 	PendingTile         = -1
 )
 
-// Event represents an action that occured in the game
-type Event int
-
-// Enum of game events
-const (
-	Move Event = iota
-	DoorOpen
-	Attack
-	Hit
-	Portal
-)
-
 // Level represents the mapping of a level
 type Level struct {
-	Tiles     [][]Tile
-	Player    *Player
-	Monsters  map[Pos]*Monster
-	Items     map[Pos][]*Item
-	Portals   map[Pos]*LevelPos
-	Events    []string
-	EventPos  int
-	LastEvent Event
-	Debug     map[Pos]bool
+	Tiles    [][]Tile
+	Player   *Player
+	Monsters map[Pos]*Monster
+	Items    map[Pos][]*Item
+	Portals  map[Pos]*LevelPos
+	Spawns   map[string]Pos
+	Locks    map[Pos]string
+	Traps    map[Pos]*Trap
+	NPCs     map[Pos]*NPC
+	Trading  *NPC
+	Talking  *Conversation
+	Journal  *Journal
+	Log      *MessageLog
+	Events   []Event
+	Debug    map[Pos]bool
+	Name     string
+	Music    string
+	Light    int
 }
 
 func loadLevels() map[string]*Level {
 	levels := make(map[string]*Level)
 
-	filenames, err := filepath.Glob("internal/game/maps/*.map")
+	filenames, err := filepath.Glob(mapsDir + "*.map")
 	if err != nil {
 		panic(err)
 	}
@@ -68,111 +68,105 @@ func loadLevels() map[string]*Level {
 		endIndex := strings.LastIndex(filename, ".map")
 		levelName := filename[startIndex+1 : endIndex]
 
-		file, err := os.Open(filename)
-		if err != nil {
-			panic(err)
-		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		lines := make([]string, 0)
-		longestRow := 0
-		index := 0
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
-			if len(lines[index]) > longestRow {
-				longestRow = len(lines[index])
-			}
-			index++
-		}
+		levels[levelName] = loadLevel(levelName)
+	}
 
-		level := &Level{
-			Tiles:    make([][]Tile, len(lines)),
-			Player:   nil,
-			Monsters: make(map[Pos]*Monster),
-			Items:    make(map[Pos][]*Item),
-			Portals:  make(map[Pos]*LevelPos),
-			Events:   make([]string, 8),
-			EventPos: 0,
-			Debug:    make(map[Pos]bool),
-		}
+	return levels
+}
 
-		for i := range level.Tiles {
-			level.Tiles[i] = make([]Tile, longestRow)
-		}
+// loadLevel parses the named level's map file, naming the level after the
+// file if the map doesn't
+func loadLevel(levelName string) *Level {
+	file, err := os.Open(mapsDir + levelName + ".map")
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
 
-		for y := range level.Tiles {
-			line := lines[y]
-			for x, c := range line {
-				t := level.Tiles[y][x]
-				t.OverlaySymbol = EmptyTile
-
-				pos := Pos{x, y}
-
-				switch c {
-				case ' ', '\t', '\n', '\r':
-					t.Symbol = EmptyTile
-				case '#':
-					t.Symbol = StoneTile
-				case '|':
-					t.OverlaySymbol = ClosedDoorTile
-					t.Symbol = PendingTile
-				case '/':
-					t.OverlaySymbol = OpenedDoorTile
-					t.Symbol = PendingTile
-				case 'u':
-					t.OverlaySymbol = UpStairTile
-					t.Symbol = PendingTile
-				case 'd':
-					t.OverlaySymbol = DownStairTile
-					t.Symbol = PendingTile
-				case 's':
-					level.Items[pos] = append(level.Items[pos], NewSword(pos))
-					t.Symbol = PendingTile
-				case 'h':
-					level.Items[pos] = append(level.Items[pos], NewHelmet(pos))
-					t.Symbol = PendingTile
-				case '.':
-					t.Symbol = DirtTile
-				case '@':
-					level.Player = NewPlayer(pos)
-					t.Symbol = PendingTile
-				case 'R':
-					level.Monsters[pos] = NewRat(pos)
-					t.Symbol = PendingTile
-				case 'S':
-					level.Monsters[pos] = NewSpider(pos)
-					t.Symbol = PendingTile
-				default:
-					panic("Invalid Character: " + string(c))
-				}
-
-				level.Tiles[y][x] = t
-			}
-		}
+	scanner := bufio.NewScanner(file)
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
 
-		for y, row := range level.Tiles {
-			for x, tile := range row {
-				if tile.Symbol == PendingTile {
-					searchPos := Pos{x, y}
-					level.Tiles[y][x].Symbol = level.bfsTile(searchPos)
-				}
-			}
-		}
+	var level *Level
+	if isVersionedMap(lines) {
+		level = parseVersionedMap(lines)
+	} else {
+		level = parseLegacyMap(lines)
+	}
 
-		levels[levelName] = level
+	if level.Name == "" {
+		level.Name = levelName
 	}
 
-	return levels
+	return level
 }
 
-// AddEvent adds a string to the event slice
-func (level *Level) AddEvent(event string) {
-	level.Events[level.EventPos] = event
-	level.EventPos++
-	if level.EventPos >= len(level.Events) {
-		level.EventPos = 0
+func newLevel(width, height int) *Level {
+	level := &Level{
+		Tiles:    make([][]Tile, height),
+		Player:   nil,
+		Monsters: make(map[Pos]*Monster),
+		Items:    make(map[Pos][]*Item),
+		Portals:  make(map[Pos]*LevelPos),
+		Spawns:   make(map[string]Pos),
+		Locks:    make(map[Pos]string),
+		Traps:    make(map[Pos]*Trap),
+		NPCs:     make(map[Pos]*NPC),
+		Debug:    make(map[Pos]bool),
+		Music:    defaultMusic,
+		Light:    defaultLight,
 	}
+
+	for i := range level.Tiles {
+		level.Tiles[i] = make([]Tile, width)
+	}
+
+	return level
+}
+
+// placeGlyph places the overlay or entity represented by c at pos, returning
+// false if c is not an overlay or entity glyph
+func (level *Level) placeGlyph(c rune, pos Pos) bool {
+	tile := &level.Tiles[pos.Y][pos.X]
+
+	switch c {
+	case '|':
+		tile.OverlaySymbol = ClosedDoorTile
+	case '/':
+		tile.OverlaySymbol = OpenedDoorTile
+	case '+':
+		tile.OverlaySymbol = LockedDoorTile
+	case '%':
+		tile.OverlaySymbol = SecretDoorTile
+	case 'u':
+		tile.OverlaySymbol = UpStairTile
+	case 'd':
+		tile.OverlaySymbol = DownStairTile
+	case SpikeTrapTile:
+		level.Traps[pos] = NewTrap(SpikeTrap)
+	case GasTrapTile:
+		level.Traps[pos] = NewTrap(GasTrap)
+	case TeleportTrapTile:
+		level.Traps[pos] = NewTrap(TeleportTrap)
+	case AlarmTrapTile:
+		level.Traps[pos] = NewTrap(AlarmTrap)
+	case '@':
+		level.Player = NewPlayer(pos)
+	case 'R':
+		level.Monsters[pos] = NewRat(pos)
+	case 'S':
+		level.Monsters[pos] = NewSpider(pos)
+	default:
+		newItem, exists := itemCatalog[c]
+		if !exists {
+			return false
+		}
+		level.Items[pos] = append(level.Items[pos], newItem(pos))
+	}
+
+	return true
 }
 
 func (level *Level) inRange(pos Pos) bool {
@@ -191,7 +185,7 @@ func (level *Level) canWalk(pos Pos) bool {
 	}
 
 	switch tile.OverlaySymbol {
-	case ClosedDoorTile:
+	case ClosedDoorTile, LockedDoorTile, SecretDoorTile:
 		return false
 	}
 
@@ -199,15 +193,117 @@ func (level *Level) canWalk(pos Pos) bool {
 		return false
 	}
 
+	if _, exists := level.NPCs[pos]; exists {
+		return false
+	}
+
 	return true
 }
 
+func (level *Level) canBash(pos Pos) bool {
+	if !level.inRange(pos) {
+		return false
+	}
+
+	return level.Tiles[pos.Y][pos.X].OverlaySymbol == ClosedDoorTile
+}
+
 func (level *Level) checkDoor(pos Pos) {
+	if !level.inRange(pos) {
+		return
+	}
+
 	tile := level.Tiles[pos.Y][pos.X]
-	if tile.OverlaySymbol == ClosedDoorTile {
-		level.LastEvent = DoorOpen
+	switch tile.OverlaySymbol {
+	case ClosedDoorTile:
+		level.addEvent(Event{Type: DoorOpened, Actor: &level.Player.Character, From: level.Player.Pos, Pos: pos})
 		level.Tiles[pos.Y][pos.X].OverlaySymbol = OpenedDoorTile
 		level.lineOfSight()
+	case LockedDoorTile:
+		level.unlockDoor(pos)
+	}
+}
+
+func (level *Level) unlockDoor(pos Pos) {
+	player := &level.Player.Character
+	keyID := level.Locks[pos]
+
+	for i, item := range player.Items {
+		if item.Type == Key && item.KeyID == keyID {
+			player.Items = append(player.Items[:i], player.Items[i+1:]...)
+			delete(level.Locks, pos)
+
+			level.Tiles[pos.Y][pos.X].OverlaySymbol = OpenedDoorTile
+			level.addEvent(Event{
+				Type:  DoorOpened,
+				Actor: player,
+				Item:  item,
+				From:  player.Pos,
+				Pos:   pos,
+				Text:  player.Name + " unlocked the door with the " + item.Name,
+			})
+			level.lineOfSight()
+			return
+		}
+	}
+
+	level.message("The door is locked")
+}
+
+func (level *Level) bashDoor(c *Character, pos Pos) {
+	level.Tiles[pos.Y][pos.X].OverlaySymbol = OpenedDoorTile
+	level.addEvent(Event{Type: DoorOpened, Actor: c, From: c.Pos, Pos: pos, Text: c.Name + " bashed open a door"})
+	level.lineOfSight()
+}
+
+// closeDoor closes an open door next to the player
+func (level *Level) closeDoor() {
+	player := level.Player
+
+	for _, pos := range player.Pos.adjacent(false) {
+		if !level.inRange(pos) || level.Tiles[pos.Y][pos.X].OverlaySymbol != OpenedDoorTile {
+			continue
+		}
+
+		// doors can't be closed on anything standing in the doorway
+		if _, exists := level.Monsters[pos]; exists || len(level.Items[pos]) > 0 {
+			continue
+		}
+
+		level.addEvent(Event{Type: DoorClosed, Actor: &player.Character, From: player.Pos, Pos: pos})
+		level.Tiles[pos.Y][pos.X].OverlaySymbol = ClosedDoorTile
+		level.lineOfSight()
+		return
+	}
+
+	level.message("No door to close!")
+}
+
+// search reveals secret doors and traps around the player
+func (level *Level) search() {
+	found := false
+
+	positions := append(level.Player.Pos.adjacent(true), level.Player.Pos)
+	for _, pos := range positions {
+		if !level.inRange(pos) {
+			continue
+		}
+
+		if level.Tiles[pos.Y][pos.X].OverlaySymbol == SecretDoorTile {
+			level.Tiles[pos.Y][pos.X].OverlaySymbol = ClosedDoorTile
+			level.addEvent(Event{Type: Discovered, Actor: &level.Player.Character, Pos: pos, Text: level.Player.Name + " found a secret door!"})
+			found = true
+		}
+
+		if trap, exists := level.Traps[pos]; exists && trap.Hidden {
+			level.revealTrap(pos)
+			level.addEvent(Event{Type: Discovered, Actor: &level.Player.Character, Pos: pos, Text: level.Player.Name + " found a " + trap.Name + "!"})
+			found = true
+		}
+	}
+
+	if !found {
+		level.message(level.Player.Name + " found nothing")
 	}
 }
 
@@ -223,7 +319,7 @@ func (level *Level) canSee(pos Pos) bool {
 	}
 
 	switch tile.OverlaySymbol {
-	case ClosedDoorTile:
+	case ClosedDoorTile, LockedDoorTile, SecretDoorTile:
 		return false
 	}
 
@@ -249,19 +345,21 @@ func (level *Level) lineOfSight() {
 
 			d := math.Sqrt(float64(xDelta*xDelta + yDelta*yDelta))
 			if d <= float64(dist) {
-				level.bresenham(pos, Pos{x, y})
+				level.reveal(pos, Pos{x, y})
 			}
 		}
 	}
 }
 
-func (level *Level) bresenham(start Pos, end Pos) {
+// bresenham returns the positions on the line from start to end, inclusive
+func bresenham(start Pos, end Pos) []Pos {
 	isSteep := math.Abs(float64(end.Y-start.Y)) > math.Abs(float64(end.X-start.X))
 	if isSteep {
 		start.X, start.Y = start.Y, start.X
 		end.X, end.Y = end.Y, end.X
 	}
 
+	deltaX := int(math.Abs(float64(end.X - start.X)))
 	deltaY := int(math.Abs(float64(end.Y - start.Y)))
 
 	err := 0
@@ -270,64 +368,77 @@ func (level *Level) bresenham(start Pos, end Pos) {
 	if start.Y >= end.Y {
 		yStep = -1
 	}
-
+	xStep := 1
 	if start.X > end.X {
-		deltaX := start.X - end.X
-		for x := start.X; x >= end.X; x-- {
-			var pos Pos
-
-			if isSteep {
-				pos = Pos{y, x}
-			} else {
-				pos = Pos{x, y}
-			}
+		xStep = -1
+	}
 
-			level.Tiles[pos.Y][pos.X].Visible = true
-			level.Tiles[pos.Y][pos.X].Seen = true
+	line := make([]Pos, 0, deltaX+1)
+	for x := start.X; ; x += xStep {
+		if isSteep {
+			line = append(line, Pos{y, x})
+		} else {
+			line = append(line, Pos{x, y})
+		}
 
-			if !level.canSee(pos) {
-				return
-			}
+		if x == end.X {
+			break
+		}
 
-			err += deltaY
-			if 2*err >= deltaX {
-				y += yStep
-				err -= deltaX
-			}
+		err += deltaY
+		if 2*err >= deltaX {
+			y += yStep
+			err -= deltaX
 		}
-	} else {
-		deltaX := end.X - start.X
-		for x := start.X; x < end.X; x++ {
-			var pos Pos
-
-			if isSteep {
-				pos = Pos{y, x}
-			} else {
-				pos = Pos{x, y}
-			}
+	}
 
-			level.Tiles[pos.Y][pos.X].Visible = true
-			level.Tiles[pos.Y][pos.X].Seen = true
+	return line
+}
 
-			if !level.canSee(pos) {
-				return
-			}
+// reveal marks the tiles from start towards end as visible, stopping at the
+// first tile that blocks sight
+func (level *Level) reveal(start Pos, end Pos) {
+	for _, pos := range bresenham(start, end) {
+		if !level.inRange(pos) {
+			return
+		}
 
-			err += deltaY
-			if 2*err >= deltaX {
-				y += yStep
-				err -= deltaX
-			}
+		level.Tiles[pos.Y][pos.X].Visible = true
+		level.Tiles[pos.Y][pos.X].Seen = true
+
+		if !level.canSee(pos) {
+			return
 		}
 	}
 }
 
+// criticalChance is how likely a melee or ranged hit is to be critical, doing
+// criticalMultiplier times the damage
+const (
+	criticalChance     = 0.1
+	criticalMultiplier = 2
+)
+
 func (level *Level) attack(c1 *Character, c2 *Character) {
+	// bows and wands are no better than fists up close
+	power := 1.0
+	if c1.Weapon != nil && (c1.Weapon.Range == 0 || c1.Weapon.Thrown) {
+		power = c1.Weapon.Power
+	}
+
+	level.hit(c1, c2, power)
+}
+
+func (level *Level) hit(c1 *Character, c2 *Character, power float64) {
 	c1.ActionPoints--
+	level.recordAttack(c1, c2)
+
+	atkPower := int(float64(c1.Damage) * power)
 
-	atkPower := c1.Damage
-	if c1.Weapon != nil {
-		atkPower = int(float64(atkPower) * c1.Weapon.Power)
+	// a lucky blow lands with double the power
+	critical := rand.Float64() < criticalChance
+	if critical {
+		atkPower *= criticalMultiplier
 	}
 
 	damage := atkPower
@@ -335,13 +446,13 @@ func (level *Level) attack(c1 *Character, c2 *Character) {
 		damage = int(float64(damage) * c2.Armor.Power)
 	}
 
-	c2.Hitpoints -= damage
-
-	if c2.Hitpoints > 0 {
-		level.AddEvent(c1.Name + " attacked " + c2.Name + " for " + strconv.Itoa(damage))
-	} else {
-		level.AddEvent(c1.Name + " killed " + c2.Name)
+	text := c1.Name + " attacked " + c2.Name + " for " + strconv.Itoa(damage)
+	if critical {
+		text = c1.Name + " critically hit " + c2.Name + " for " + strconv.Itoa(damage)
 	}
+
+	c2.Hitpoints -= damage
+	level.recordDamage(c1, c2, damage, critical, text, c1.Name+" attacked "+c2.Name, c1.Name+" killed "+c2.Name)
 }
 
 func (level *Level) equip(c *Character, targetItem *Item) {
@@ -358,16 +469,37 @@ func (level *Level) equip(c *Character, targetItem *Item) {
 	}
 }
 
-func (level *Level) moveItem(targetItem *Item, character *Character) {
+// moveItem picks up an item from the floor, returning false if the character
+// has no room for it
+func (level *Level) moveItem(targetItem *Item, character *Character) bool {
 	pos := character.Pos
 
+	// gold goes straight into the purse, stacks merge with what's carried
+	if !character.addItem(targetItem) {
+		level.message(character.Name + " has no room for the " + targetItem.Name)
+		return false
+	}
+
 	for i, item := range level.Items[pos] {
 		if item == targetItem {
 			level.Items[pos] = append(level.Items[pos][:i], level.Items[pos][i+1:]...)
+			break
 		}
 	}
 
-	character.Items = append(character.Items, targetItem)
+	level.addEvent(Event{
+		Type:  ItemPickedUp,
+		Actor: character,
+		Item:  targetItem,
+		Pos:   pos,
+		Text:  character.Name + " picked up " + targetItem.Name,
+	})
+
+	if character == &level.Player.Character && targetItem.Type != Gold {
+		level.recordPickup(targetItem.Name)
+	}
+
+	return true
 }
 
 func (level *Level) dropItem(targetItem *Item, character *Character) {
@@ -379,36 +511,85 @@ func (level *Level) dropItem(targetItem *Item, character *Character) {
 		}
 	}
 
-	level.Items[pos] = append(level.Items[pos], targetItem)
+	level.Items[pos] = stackItems(level.Items[pos], targetItem)
+	level.addEvent(Event{
+		Type:  ItemDropped,
+		Actor: character,
+		Item:  targetItem,
+		Pos:   pos,
+		Text:  character.Name + " dropped " + targetItem.Name,
+	})
 }
 
 func (level *Level) resolveMove(pos Pos) {
+	if npc, exists := level.NPCs[pos]; exists {
+		level.talk(npc)
+		return
+	}
+
 	monster, exists := level.Monsters[pos]
 	if exists {
-		level.LastEvent = Attack
 		level.attack(&level.Player.Character, &monster.Character)
-		if monster.Hitpoints <= 0 {
-			droppedItems := level.Items[monster.Pos]
-			for _, item := range monster.Items {
-				item.Pos = monster.Pos
-				droppedItems = append(droppedItems, item)
-			}
-			level.Items[monster.Pos] = droppedItems
-			delete(level.Monsters, monster.Pos)
-		}
-		if level.Player.Hitpoints <= 0 {
-			panic("You Died!")
-		}
+		level.checkDeath(&monster.Character)
+		level.checkDeath(&level.Player.Character)
 	} else if level.canWalk(pos) {
-		level.LastEvent = Move
+		level.addEvent(Event{Type: Moved, Actor: &level.Player.Character, From: level.Player.Pos, Pos: pos})
 		level.Player.Move(level, pos)
 		level.lineOfSight()
+		level.spotTraps()
 	} else {
 		level.checkDoor(pos)
 	}
 
+	level.updateMonsters()
+}
+
+// checkDeath ends the game if the player has died, or removes a dead monster
+// leaving its items on the floor
+func (level *Level) checkDeath(c *Character) {
+	if c.Hitpoints > 0 {
+		return
+	}
+
+	if c == &level.Player.Character {
+		panic("You Died!")
+	}
+
+	monster, exists := level.Monsters[c.Pos]
+	if !exists || &monster.Character != c {
+		return
+	}
+
+	droppedItems := level.Items[monster.Pos]
+	for _, item := range monster.Items {
+		item.Pos = monster.Pos
+		droppedItems = stackItems(droppedItems, item)
+	}
+	if monster.Gold > 0 {
+		gold := NewGold(monster.Pos)
+		gold.Count = monster.Gold
+		droppedItems = stackItems(droppedItems, gold)
+	}
+	level.Items[monster.Pos] = droppedItems
+	delete(level.Monsters, monster.Pos)
+	level.recordKill(monster.Name)
+}
+
+func (level *Level) updateMonsters() {
+	level.Player.tick()
+	level.checkEncumbrance()
+
+	// monsters can be moved or killed by traps while updating
+	monsters := make([]*Monster, 0, len(level.Monsters))
 	for _, monster := range level.Monsters {
-		monster.Update(level)
+		monster.tick()
+		monsters = append(monsters, monster)
+	}
+
+	for _, monster := range monsters {
+		if monster.Hitpoints > 0 {
+			monster.Update(level)
+		}
 	}
 }
 
@@ -432,7 +613,7 @@ func (level *Level) bfsTile(start Pos) rune {
 
 		queue = queue[1:]
 
-		for _, neighbor := range level.getNeighbors(current) {
+		for _, neighbor := range level.getNeighbors(current, level.canWalk) {
 			if !visited[neighbor] {
 				queue = append(queue, neighbor)
 				visited[neighbor] = true
@@ -443,7 +624,7 @@ func (level *Level) bfsTile(start Pos) rune {
 	return DirtTile
 }
 
-func (level *Level) astar(start Pos, goal Pos) []Pos {
+func (level *Level) astar(start Pos, goal Pos, canWalk func(Pos) bool) []Pos {
 	queue := make(posPriorityQueue, 0, 8)
 	queue = queue.push(start, 1)
 
@@ -473,7 +654,7 @@ func (level *Level) astar(start Pos, goal Pos) []Pos {
 			return path
 		}
 
-		for _, neighbor := range level.getNeighbors(current) {
+		for _, neighbor := range level.getNeighbors(current, canWalk) {
 			newCost := cost[current] + 1 // always 1 for now
 			if _, exists := cost[neighbor]; !exists || newCost < cost[neighbor] {
 				cost[neighbor] = newCost
@@ -489,27 +670,13 @@ func (level *Level) astar(start Pos, goal Pos) []Pos {
 	return nil
 }
 
-func (level *Level) getNeighbors(pos Pos) []Pos {
+func (level *Level) getNeighbors(pos Pos, canWalk func(Pos) bool) []Pos {
 	neighbors := make([]Pos, 0, 8)
 
-	right := Pos{X: pos.X + 1, Y: pos.Y}
-	if level.canWalk(right) {
-		neighbors = append(neighbors, right)
-	}
-
-	left := Pos{X: pos.X - 1, Y: pos.Y}
-	if level.canWalk(left) {
-		neighbors = append(neighbors, left)
-	}
-
-	up := Pos{X: pos.X, Y: pos.Y - 1}
-	if level.canWalk(up) {
-		neighbors = append(neighbors, up)
-	}
-
-	down := Pos{X: pos.X, Y: pos.Y + 1}
-	if level.canWalk(down) {
-		neighbors = append(neighbors, down)
+	for _, neighbor := range pos.adjacent(false) {
+		if canWalk(neighbor) {
+			neighbors = append(neighbors, neighbor)
+		}
 	}
 
 	return neighbors
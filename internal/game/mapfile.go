@@ -0,0 +1,504 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Versioned map files start with a header of "key: value" lines followed by a
+// tile layer and an entity layer, for example:
+//
+//	version: 1
+//	name: Dungeon Entrance
+//	music: ambient.ogg
+//	light: 255
+//	spawn: start 3,3
+//	entity: h 1,1
+//	lock: gold 2,1
+//	key: gold 1,1
+//	[tiles]
+//	#####
+//	#...#
+//	#####
+//	[entities]
+//
+//	 @ R
+//
+// The tile layer only holds terrain (walls and floor), so the floor under
+// every door, stair, item and monster is explicit. The entity layer uses the
+// same glyphs as the legacy format with spaces for empty cells. Cells holding
+// more than one entity list the extras as "entity" header lines. Locked doors
+// and keys that need a named match are placed with "lock" and "key" lines.
+// Traps are placed with their overlay glyph and always start hidden. Merchants
+// are placed with "merchant" lines listing the glyphs of the items they sell,
+// and "greeting" lines set what the merchant at a position says when the
+// player walks up to them. NPCs given a "dialogue" line at their position
+// hold a conversation from that dialogue file before trading. Monsters listed in "asleep" lines don't move until they see
+// the player or an alarm wakes them. Items stacked to a count other than the
+// one their glyph starts with are placed with "stack" lines, for example
+// "stack: a 3 1,1" for three arrows. The player starts at the "start" spawn,
+// or at the "@" glyph in maps without one.
+const mapVersion = 1
+
+const (
+	defaultMusic = "ambient.ogg"
+	defaultLight = 255
+)
+
+const (
+	tilesSection    = "[tiles]"
+	entitiesSection = "[entities]"
+)
+
+func isVersionedMap(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(lines[0], "version:")
+}
+
+// parseLegacyMap parses a map where each entity glyph also consumes the tile
+// glyph, guessing the floor underneath with bfsTile
+func parseLegacyMap(lines []string) *Level {
+	longestRow := 0
+	for _, line := range lines {
+		if len(line) > longestRow {
+			longestRow = len(line)
+		}
+	}
+
+	level := newLevel(longestRow, len(lines))
+
+	for y, line := range lines {
+		for x, c := range line {
+			pos := Pos{x, y}
+
+			switch c {
+			case ' ', '\t', '\n', '\r':
+				level.Tiles[y][x].Symbol = EmptyTile
+			case '#':
+				level.Tiles[y][x].Symbol = StoneTile
+			case '.':
+				level.Tiles[y][x].Symbol = DirtTile
+			default:
+				if !level.placeGlyph(c, pos) {
+					panic("Invalid Character: " + string(c))
+				}
+				level.Tiles[y][x].Symbol = PendingTile
+			}
+		}
+	}
+
+	for y, row := range level.Tiles {
+		for x, tile := range row {
+			if tile.Symbol == PendingTile {
+				searchPos := Pos{x, y}
+				level.Tiles[y][x].Symbol = level.bfsTile(searchPos)
+			}
+		}
+	}
+
+	return level
+}
+
+// parseVersionedMap parses a map with a metadata header, tile layer and
+// entity layer
+func parseVersionedMap(lines []string) *Level {
+	header := make([]string, 0)
+	tileLines := make([]string, 0)
+	entityLines := make([]string, 0)
+
+	section := ""
+	for _, line := range lines {
+		switch strings.TrimSpace(line) {
+		case tilesSection, entitiesSection:
+			section = strings.TrimSpace(line)
+			continue
+		}
+
+		switch section {
+		case tilesSection:
+			tileLines = append(tileLines, line)
+		case entitiesSection:
+			entityLines = append(entityLines, line)
+		default:
+			header = append(header, line)
+		}
+	}
+
+	longestRow := 0
+	for _, line := range tileLines {
+		if len(line) > longestRow {
+			longestRow = len(line)
+		}
+	}
+
+	level := newLevel(longestRow, len(tileLines))
+	extraEntities := make([]string, 0)
+	greetings := make(map[Pos]string)
+	dialogues := make(map[Pos]string)
+
+	for _, line := range header {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		sep := strings.Index(line, ":")
+		if sep == -1 {
+			panic("Invalid header line: " + line)
+		}
+		key := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(line[sep+1:])
+
+		switch key {
+		case "version":
+			version, err := strconv.Atoi(value)
+			if err != nil {
+				panic(err)
+			}
+			if version > mapVersion {
+				panic("Unsupported map version: " + value)
+			}
+		case "name":
+			level.Name = value
+		case "music":
+			level.Music = value
+		case "light":
+			light, err := strconv.Atoi(value)
+			if err != nil {
+				panic(err)
+			}
+			level.Light = light
+		case "spawn":
+			fields := strings.Fields(value)
+			if len(fields) != 2 {
+				panic("Invalid spawn: " + value)
+			}
+			level.Spawns[fields[0]] = parsePos(fields[1])
+		case "entity", "lock", "key", "asleep", "stack":
+			extraEntities = append(extraEntities, key+" "+value)
+		case "merchant":
+			fields := strings.Fields(value)
+			if len(fields) < 2 {
+				panic("Invalid merchant: " + value)
+			}
+
+			pos := parsePos(fields[1])
+			stock := make([]*Item, 0)
+			for _, glyphs := range fields[2:] {
+				for _, glyph := range glyphs {
+					newItem, exists := itemCatalog[glyph]
+					if !exists {
+						panic("Invalid Character: " + string(glyph))
+					}
+					stock = append(stock, newItem(pos))
+				}
+			}
+
+			level.NPCs[pos] = NewMerchant(pos, fields[0], stock)
+		case "greeting":
+			fields := strings.SplitN(value, " ", 2)
+			if len(fields) != 2 {
+				panic("Invalid greeting: " + value)
+			}
+			greetings[parsePos(fields[0])] = strings.TrimSpace(fields[1])
+		case "dialogue":
+			fields := strings.Fields(value)
+			if len(fields) != 2 {
+				panic("Invalid dialogue: " + value)
+			}
+			dialogues[parsePos(fields[0])] = fields[1]
+		default:
+			panic("Unknown header key: " + key)
+		}
+	}
+
+	for y, line := range tileLines {
+		for x, c := range line {
+			switch c {
+			case ' ':
+				level.Tiles[y][x].Symbol = EmptyTile
+			case '#':
+				level.Tiles[y][x].Symbol = StoneTile
+			case '.':
+				level.Tiles[y][x].Symbol = DirtTile
+			default:
+				panic("Invalid tile: " + string(c))
+			}
+		}
+	}
+
+	for y, line := range entityLines {
+		for x, c := range line {
+			if c == ' ' {
+				continue
+			}
+
+			pos := Pos{x, y}
+			if !level.inRange(pos) {
+				panic("Entity outside of tile layer: " + string(c))
+			}
+			if !level.placeGlyph(c, pos) {
+				panic("Invalid Character: " + string(c))
+			}
+		}
+	}
+
+	for _, value := range extraEntities {
+		fields := strings.Fields(value)
+		if fields[0] == "asleep" {
+			continue
+		}
+
+		if fields[0] == "stack" {
+			if len(fields) != 4 {
+				panic("Invalid stack: " + value)
+			}
+
+			pos := parsePos(fields[3])
+			if !level.inRange(pos) {
+				panic("Entity outside of tile layer: " + value)
+			}
+
+			glyph := []rune(fields[1])
+			if len(glyph) != 1 || itemCatalog[glyph[0]] == nil {
+				panic("Invalid Character: " + fields[1])
+			}
+
+			count, err := strconv.Atoi(fields[2])
+			if err != nil {
+				panic(err)
+			}
+
+			item := itemCatalog[glyph[0]](pos)
+			item.Count = count
+			level.Items[pos] = append(level.Items[pos], item)
+			continue
+		}
+
+		if len(fields) != 3 {
+			panic("Invalid entity: " + value)
+		}
+
+		pos := parsePos(fields[2])
+		if !level.inRange(pos) {
+			panic("Entity outside of tile layer: " + value)
+		}
+
+		switch fields[0] {
+		case "lock":
+			level.Tiles[pos.Y][pos.X].OverlaySymbol = LockedDoorTile
+			level.Locks[pos] = fields[1]
+		case "key":
+			level.Items[pos] = append(level.Items[pos], NewKey(pos, fields[1]))
+		default:
+			glyph := []rune(fields[1])
+			if len(glyph) != 1 || !level.placeGlyph(glyph[0], pos) {
+				panic("Invalid Character: " + fields[1])
+			}
+		}
+	}
+
+	// monsters are put to sleep once every entity is placed
+	for _, value := range extraEntities {
+		fields := strings.Fields(value)
+		if fields[0] != "asleep" {
+			continue
+		}
+		if len(fields) != 2 {
+			panic("Invalid asleep: " + value)
+		}
+
+		monster, exists := level.Monsters[parsePos(fields[1])]
+		if !exists {
+			panic("No monster asleep at: " + fields[1])
+		}
+		monster.Asleep = true
+	}
+
+	for pos, npc := range level.NPCs {
+		if !level.inRange(pos) {
+			panic("Merchant outside of tile layer: " + npc.Name)
+		}
+		if greeting, exists := greetings[pos]; exists {
+			npc.Greeting = greeting
+		}
+		if filename, exists := dialogues[pos]; exists {
+			npc.Dialogue = loadDialogue(filename)
+		}
+	}
+
+	if level.Player == nil {
+		if start, exists := level.Spawns["start"]; exists {
+			level.Player = NewPlayer(start)
+		}
+	}
+
+	return level
+}
+
+// parsePos parses a position written as "x,y"
+func parsePos(s string) Pos {
+	coords := strings.Split(s, ",")
+	if len(coords) != 2 {
+		panic("Invalid position: " + s)
+	}
+
+	x, err := strconv.Atoi(strings.TrimSpace(coords[0]))
+	if err != nil {
+		panic(err)
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(coords[1]))
+	if err != nil {
+		panic(err)
+	}
+
+	return Pos{X: x, Y: y}
+}
+
+// spawnAt returns the name of the spawn point at pos, or an empty string
+func (level *Level) spawnAt(pos Pos) string {
+	names := make([]string, 0)
+	for name, spawn := range level.Spawns {
+		if spawn == pos {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	sort.Strings(names)
+	return names[0]
+}
+
+// writeMap writes the level in the versioned map format. The player is
+// written at the start spawn rather than wherever it is standing.
+func (level *Level) writeMap(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	playerPos, hasPlayer := level.Spawns["start"]
+	if !hasPlayer && level.Player != nil {
+		playerPos, hasPlayer = level.Player.Pos, true
+	}
+
+	fmt.Fprintf(writer, "version: %d\n", mapVersion)
+	fmt.Fprintf(writer, "name: %s\n", level.Name)
+	fmt.Fprintf(writer, "music: %s\n", level.Music)
+	fmt.Fprintf(writer, "light: %d\n", level.Light)
+
+	spawns := make([]string, 0, len(level.Spawns))
+	for name := range level.Spawns {
+		spawns = append(spawns, name)
+	}
+	sort.Strings(spawns)
+	for _, name := range spawns {
+		pos := level.Spawns[name]
+		fmt.Fprintf(writer, "spawn: %s %d,%d\n", name, pos.X, pos.Y)
+	}
+
+	npcs := make([]Pos, 0, len(level.NPCs))
+	for pos := range level.NPCs {
+		npcs = append(npcs, pos)
+	}
+	sort.Slice(npcs, func(i, j int) bool {
+		if npcs[i].Y != npcs[j].Y {
+			return npcs[i].Y < npcs[j].Y
+		}
+		return npcs[i].X < npcs[j].X
+	})
+	for _, pos := range npcs {
+		npc := level.NPCs[pos]
+		stock := make([]rune, 0, len(npc.Items))
+		for _, item := range npc.Items {
+			stock = append(stock, item.Symbol)
+		}
+		fmt.Fprintf(writer, "merchant: %s %d,%d %s\n", npc.Name, pos.X, pos.Y, string(stock))
+		fmt.Fprintf(writer, "greeting: %d,%d %s\n", pos.X, pos.Y, npc.Greeting)
+		if npc.Dialogue != nil {
+			fmt.Fprintf(writer, "dialogue: %d,%d %s\n", pos.X, pos.Y, npc.Dialogue.File)
+		}
+	}
+
+	tileLines := make([]string, len(level.Tiles))
+	entityLines := make([]string, len(level.Tiles))
+
+	for y, row := range level.Tiles {
+		tileLine := make([]rune, len(row))
+		entityLine := make([]rune, len(row))
+
+		for x, tile := range row {
+			pos := Pos{x, y}
+
+			switch tile.Symbol {
+			case StoneTile:
+				tileLine[x] = '#'
+			case DirtTile:
+				tileLine[x] = '.'
+			default:
+				tileLine[x] = ' '
+			}
+
+			// the entity layer holds one glyph per cell, the rest go in the header
+			glyphs := make([]rune, 0)
+			if lock := level.Locks[pos]; lock != "" && tile.OverlaySymbol == LockedDoorTile {
+				fmt.Fprintf(writer, "lock: %s %d,%d\n", lock, x, y)
+			} else if tile.OverlaySymbol != EmptyTile && level.Traps[pos] == nil {
+				glyphs = append(glyphs, tile.OverlaySymbol)
+			}
+			if trap, exists := level.Traps[pos]; exists {
+				glyphs = append(glyphs, trap.Symbol)
+			}
+			if hasPlayer && playerPos == pos {
+				glyphs = append(glyphs, PlayerTile)
+			}
+			if monster, exists := level.Monsters[pos]; exists {
+				glyphs = append(glyphs, monster.Symbol)
+				if monster.Asleep {
+					fmt.Fprintf(writer, "asleep: %d,%d\n", x, y)
+				}
+			}
+			for _, item := range level.Items[pos] {
+				if item.Type == Key && item.KeyID != "" {
+					fmt.Fprintf(writer, "key: %s %d,%d\n", item.KeyID, x, y)
+					continue
+				}
+				if newItem, exists := itemCatalog[item.Symbol]; exists && item.Count != newItem(pos).Count {
+					fmt.Fprintf(writer, "stack: %c %d %d,%d\n", item.Symbol, item.Count, x, y)
+					continue
+				}
+				glyphs = append(glyphs, item.Symbol)
+			}
+
+			entityLine[x] = ' '
+			for i, glyph := range glyphs {
+				if i == 0 {
+					entityLine[x] = glyph
+				} else {
+					fmt.Fprintf(writer, "entity: %c %d,%d\n", glyph, x, y)
+				}
+			}
+		}
+
+		tileLines[y] = strings.TrimRight(string(tileLine), " ")
+		entityLines[y] = strings.TrimRight(string(entityLine), " ")
+	}
+
+	fmt.Fprintln(writer, tilesSection)
+	for _, line := range tileLines {
+		fmt.Fprintln(writer, line)
+	}
+
+	for len(entityLines) > 0 && entityLines[len(entityLines)-1] == "" {
+		entityLines = entityLines[:len(entityLines)-1]
+	}
+
+	fmt.Fprintln(writer, entitiesSection)
+	for _, line := range entityLines {
+		fmt.Fprintln(writer, line)
+	}
+
+	return writer.Flush()
+}
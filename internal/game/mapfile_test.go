@@ -0,0 +1,97 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+// rewriteMap writes a level and parses it back, returning the written map
+// and the level read from it
+func rewriteMap(t *testing.T, level *Level) (string, *Level) {
+	var buf bytes.Buffer
+	err := level.writeMap(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := buf.String()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return written, parseVersionedMap(lines)
+}
+
+func TestWriteMapRoundTrip(t *testing.T) {
+	for _, name := range []string{"level1", "level2"} {
+		original := loadLevel(name)
+		written, level := rewriteMap(t, original)
+
+		if len(level.Monsters) != len(original.Monsters) || len(level.Items) != len(original.Items) ||
+			len(level.NPCs) != len(original.NPCs) || len(level.Traps) != len(original.Traps) ||
+			len(level.Locks) != len(original.Locks) || len(level.Spawns) != len(original.Spawns) {
+			t.Errorf("%s lost entities when written:\n%s", name, written)
+		}
+		if level.Player.Pos != original.Player.Pos {
+			t.Errorf("%s player moved from %v to %v", name, original.Player.Pos, level.Player.Pos)
+		}
+
+		rewritten, _ := rewriteMap(t, level)
+		if rewritten != written {
+			t.Errorf("%s changed when read back and written again:\n%s", name, rewritten)
+		}
+	}
+}
+
+func TestWriteMapKeepsSpawnAndStacks(t *testing.T) {
+	level := loadLevel("level1")
+	start := level.Spawns["start"]
+
+	// walk the player off the start and leave part of a stack of arrows there
+	level.Player.Pos = Pos{X: start.X + 1, Y: start.Y}
+	arrows := NewArrows(start)
+	arrows.Count = 3
+	level.Items[start] = append(level.Items[start], arrows)
+
+	_, level = rewriteMap(t, level)
+	if level.Player.Pos != start {
+		t.Errorf("player starts at %v, want the start spawn %v", level.Player.Pos, start)
+	}
+
+	items := level.Items[start]
+	if len(items) == 0 || items[len(items)-1].Symbol != 'a' {
+		t.Fatalf("arrows weren't written, found %v", items)
+	}
+	if count := items[len(items)-1].Count; count != 3 {
+		t.Errorf("arrows read back with a count of %d, want 3", count)
+	}
+}
+
+func TestWriteMapKeepsMerchantsApart(t *testing.T) {
+	level := parseVersionedMap([]string{
+		"version: 1",
+		"merchant: Grimble 1,1 p",
+		"merchant: Grimble 3,1 a",
+		"greeting: 1,1 Potions here",
+		"greeting: 3,1 Arrows here",
+		"[tiles]",
+		"#####",
+		"#...#",
+		"#####",
+		"[entities]",
+	})
+
+	_, level = rewriteMap(t, level)
+	for pos, want := range map[Pos]string{{X: 1, Y: 1}: "Potions here", {X: 3, Y: 1}: "Arrows here"} {
+		npc, exists := level.NPCs[pos]
+		if !exists {
+			t.Fatalf("no merchant at %v", pos)
+		}
+		if npc.Greeting != want {
+			t.Errorf("merchant at %v greets with %q, want %q", pos, npc.Greeting, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package game
+
+import "strconv"
+
+// maxLogEntries is how much message history is kept
+const maxLogEntries = 500
+
+// MessageCategory groups log messages for colouring and filtering
+type MessageCategory int
+
+// Enum of message categories
+const (
+	GeneralMessages MessageCategory = iota
+	CombatMessages
+	ItemMessages
+	QuestMessages
+)
+
+// MessageCategories lists every category with its name
+var MessageCategories = []string{
+	GeneralMessages: "General",
+	CombatMessages:  "Combat",
+	ItemMessages:    "Items",
+	QuestMessages:   "Quests",
+}
+
+// LogEntry is a line in the message log. Count is how many times in a row
+// the same message was logged, Turn is the latest turn it was logged on.
+// Summary is shown in place of Text once it has repeated.
+type LogEntry struct {
+	Turn     int
+	Text     string
+	Summary  string
+	Category MessageCategory
+	Count    int
+
+	key logKey
+}
+
+// logKey is what repeats of a message are merged on
+type logKey struct {
+	eventType EventType
+	actor     *Character
+	target    *Character
+	text      string
+}
+
+// String returns the entry's text, with how many times it repeated
+func (entry *LogEntry) String() string {
+	if entry.Count > 1 {
+		text := entry.Text
+		if entry.Summary != "" {
+			text = entry.Summary
+		}
+		return text + " x" + strconv.Itoa(entry.Count)
+	}
+
+	return entry.Text
+}
+
+// MessageLog is the history of messages shared by every level
+type MessageLog struct {
+	Turn    int
+	Entries []*LogEntry
+}
+
+func newMessageLog() *MessageLog {
+	return &MessageLog{
+		Entries: make([]*LogEntry, 0),
+	}
+}
+
+// add logs an event for the current turn. The same kind of event by the same
+// actor on the same target as the last one is merged into it, going by its
+// summary so amounts that differ each time don't keep them apart.
+func (log *MessageLog) add(event Event) {
+	key := logKey{eventType: event.Type, actor: event.Actor, target: event.Target, text: event.Text}
+	if event.Summary != "" {
+		key.text = event.Summary
+	}
+
+	if n := len(log.Entries); n > 0 {
+		last := log.Entries[n-1]
+		if last.key == key {
+			last.Count++
+			last.Turn = log.Turn
+			last.Text = event.Text
+			return
+		}
+	}
+
+	log.Entries = append(log.Entries, &LogEntry{
+		Turn:     log.Turn,
+		Text:     event.Text,
+		Summary:  event.Summary,
+		Category: event.Type.Category(),
+		Count:    1,
+		key:      key,
+	})
+
+	if len(log.Entries) > maxLogEntries {
+		log.Entries = log.Entries[len(log.Entries)-maxLogEntries:]
+	}
+}
+
+// Category returns the log category messages about an event belong to
+func (t EventType) Category() MessageCategory {
+	switch t {
+	case Attacked, Damaged, Killed, Healed, AbilityCast, TrapTriggered:
+		return CombatMessages
+	case ItemPickedUp, ItemDropped, Traded:
+		return ItemMessages
+	case QuestUpdated:
+		return QuestMessages
+	default:
+		return GeneralMessages
+	}
+}
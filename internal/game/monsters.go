@@ -3,11 +3,14 @@ package game
 // Monster represents a monster in the game
 type Monster struct {
 	Character
+	CanBashDoors bool
+	Asleep       bool
+	Feared       int
 }
 
 // NewRat creates a Rat monster
 func NewRat(p Pos) *Monster {
-	return &Monster{
+	monster := &Monster{
 		Character: Character{
 			Entity: Entity{
 				Pos:    p,
@@ -15,17 +18,22 @@ func NewRat(p Pos) *Monster {
 				Symbol: 'R',
 			},
 			Hitpoints:    5,
+			MaxHitpoints: 5,
+			Gold:         2,
 			Damage:       1,
 			Speed:        2.0,
 			ActionPoints: 0,
 			SightRange:   10,
 		},
 	}
+	monster.learnAbilities()
+
+	return monster
 }
 
 // NewSpider creates a Spider monster
 func NewSpider(p Pos) *Monster {
-	return &Monster{
+	monster := &Monster{
 		Character: Character{
 			Entity: Entity{
 				Pos:    p,
@@ -33,20 +41,58 @@ func NewSpider(p Pos) *Monster {
 				Symbol: 'S',
 			},
 			Hitpoints:    10,
+			MaxHitpoints: 10,
+			Mana:         6,
+			MaxMana:      6,
+			Gold:         5,
 			Damage:       2,
 			Speed:        1.0,
 			ActionPoints: 0,
 			SightRange:   10,
 		},
+		CanBashDoors: true,
 	}
+	monster.learnAbilities()
+
+	return monster
 }
 
 // Update updates the monsters position relative to the player
 func (m *Monster) Update(level *Level) {
-	m.ActionPoints += m.Speed
+	// monsters placed asleep wake once they can see the player, or when
+	// an alarm goes off
+	if m.Asleep {
+		xDelta := m.X - level.Player.X
+		yDelta := m.Y - level.Player.Y
+		inRange := xDelta*xDelta+yDelta*yDelta <= m.SightRange*m.SightRange
+		if !inRange || !level.Tiles[m.Y][m.X].Visible {
+			return
+		}
+		m.Asleep = false
+	}
+
+	// monsters get more done while an encumbered player plods along
+	m.ActionPoints += m.Speed / level.Player.Speed
+
+	if m.Feared > 0 {
+		m.Feared--
+		m.flee(level)
+		return
+	}
+
+	if m.castAtPlayer(level) {
+		return
+	}
+
+	canWalk := level.canWalk
+	if m.CanBashDoors {
+		canWalk = func(pos Pos) bool {
+			return level.canWalk(pos) || level.canBash(pos)
+		}
+	}
 
 	playerPos := level.Player.Pos
-	positions := level.astar(m.Pos, playerPos)
+	positions := level.astar(m.Pos, playerPos, canWalk)
 
 	if len(positions) == 0 {
 		m.Pass()
@@ -57,20 +103,35 @@ func (m *Monster) Update(level *Level) {
 	ap := int(m.ActionPoints)
 	for i := 0; i < ap; i++ {
 		if moveIndex < len(positions) {
-			m.Move(level, positions[moveIndex])
+			to := positions[moveIndex]
+			m.Move(level, to)
 			moveIndex++
 			m.ActionPoints--
+
+			// stop following the path if blocked, busy with a door or killed
+			if m.Pos != to || m.Hitpoints <= 0 {
+				break
+			}
 		}
 	}
 }
 
 // Move moves the monster to a given position
 func (m *Monster) Move(level *Level, to Pos) {
+	if level.canBash(to) {
+		if m.CanBashDoors {
+			level.bashDoor(&m.Character, to)
+		}
+		return
+	}
+
 	// check if valid tile
 	if _, exists := level.Monsters[to]; !exists && to != level.Player.Pos {
+		level.addEvent(Event{Type: Moved, Actor: &m.Character, From: m.Pos, Pos: to})
 		delete(level.Monsters, m.Pos)
 		level.Monsters[to] = m
 		m.Pos = to
+		level.triggerTrap(&m.Character, to)
 	} else if to == level.Player.Pos {
 		level.attack(&m.Character, &level.Player.Character)
 	}
@@ -80,3 +141,63 @@ func (m *Monster) Move(level *Level, to Pos) {
 func (m *Monster) Pass() {
 	m.ActionPoints -= m.Speed
 }
+
+// castAtPlayer uses the first ready ability that can reach the player
+func (m *Monster) castAtPlayer(level *Level) bool {
+	playerPos := level.Player.Pos
+
+	for _, ability := range m.Abilities {
+		if !m.canCast(ability) {
+			continue
+		}
+
+		target := playerPos
+		if ability.Effect == HealEffect {
+			if m.Hitpoints >= m.MaxHitpoints {
+				continue
+			}
+			target = m.Pos
+		} else if ability.Effect != DamageEffect {
+			continue
+		}
+
+		xDelta := target.X - m.X
+		yDelta := target.Y - m.Y
+		if xDelta*xDelta+yDelta*yDelta > ability.Range*ability.Range {
+			continue
+		}
+
+		if target != m.Pos {
+			path := level.LineOfFire(m.Pos, target)
+			if len(path) == 0 || path[len(path)-1] != target {
+				continue
+			}
+		}
+
+		return level.cast(&m.Character, ability, target)
+	}
+
+	return false
+}
+
+// flee moves the monster one step further away from the player
+func (m *Monster) flee(level *Level) {
+	playerPos := level.Player.Pos
+	distance := func(pos Pos) int {
+		xDelta := pos.X - playerPos.X
+		yDelta := pos.Y - playerPos.Y
+		return xDelta*xDelta + yDelta*yDelta
+	}
+
+	best := m.Pos
+	for _, pos := range level.getNeighbors(m.Pos, level.canWalk) {
+		if pos != playerPos && distance(pos) > distance(best) {
+			best = pos
+		}
+	}
+
+	if best != m.Pos {
+		m.Move(level, best)
+	}
+	m.ActionPoints--
+}
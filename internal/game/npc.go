@@ -0,0 +1,125 @@
+package game
+
+import "strconv"
+
+// NPC represents a non-hostile character the player can talk and trade with
+type NPC struct {
+	Character
+	Greeting string
+	Dialogue *Dialogue
+}
+
+// NewMerchant creates a merchant NPC who trades the given stock
+func NewMerchant(p Pos, name string, stock []*Item) *NPC {
+	return &NPC{
+		Character: Character{
+			Entity: Entity{
+				Pos:    p,
+				Name:   name,
+				Symbol: 'N',
+			},
+			Hitpoints:    10,
+			MaxHitpoints: 10,
+			Gold:         100,
+			Items:        stock,
+		},
+		Greeting: "Care to trade?",
+	}
+}
+
+// talk starts a conversation with an NPC, or trading if they have nothing to say
+func (level *Level) talk(npc *NPC) {
+	player := &level.Player.Character
+	event := Event{Type: Talked, Actor: player, Target: &npc.Character, From: player.Pos, Pos: npc.Pos}
+	if npc.Dialogue != nil {
+		level.Talking = &Conversation{NPC: npc, Node: npc.Dialogue.Nodes[startNode]}
+		level.addEvent(event)
+		return
+	}
+
+	level.Trading = npc
+	event.Text = npc.Name + ": " + npc.Greeting
+	level.addEvent(event)
+}
+
+func (level *Level) endTrade() {
+	level.Trading = nil
+}
+
+// buy moves an item from the merchant being traded with to the player
+func (level *Level) buy(targetItem *Item) {
+	merchant := level.Trading
+	player := &level.Player.Character
+	if merchant == nil {
+		return
+	}
+
+	for i, item := range merchant.Items {
+		if item != targetItem {
+			continue
+		}
+
+		price := item.Price()
+		if player.Gold < price {
+			level.message("You can't afford the " + item.Name)
+			return
+		}
+		if !player.canCarry(item) {
+			level.message("You have no room for the " + item.Name)
+			return
+		}
+
+		merchant.Items = append(merchant.Items[:i], merchant.Items[i+1:]...)
+		merchant.Gold += price
+		player.Gold -= price
+		player.addItem(item)
+		level.addEvent(Event{
+			Type:   Traded,
+			Actor:  player,
+			Target: &merchant.Character,
+			Item:   item,
+			Amount: -price,
+			Text:   player.Name + " bought " + item.Name + " for " + strconv.Itoa(price) + " gold",
+		})
+		return
+	}
+}
+
+// sell moves an item from the player to the merchant being traded with
+func (level *Level) sell(targetItem *Item) {
+	merchant := level.Trading
+	player := &level.Player.Character
+	if merchant == nil {
+		return
+	}
+
+	for i, item := range player.Items {
+		if item != targetItem {
+			continue
+		}
+
+		price := item.SellPrice()
+		if price <= 0 {
+			level.message(merchant.Name + " isn't interested in the " + item.Name)
+			return
+		}
+		if merchant.Gold < price {
+			level.message(merchant.Name + " can't afford the " + item.Name)
+			return
+		}
+
+		player.Items = append(player.Items[:i], player.Items[i+1:]...)
+		player.Gold += price
+		merchant.Gold -= price
+		merchant.addItem(item)
+		level.addEvent(Event{
+			Type:   Traded,
+			Actor:  player,
+			Target: &merchant.Character,
+			Item:   item,
+			Amount: price,
+			Text:   player.Name + " sold " + item.Name + " for " + strconv.Itoa(price) + " gold",
+		})
+		return
+	}
+}
@@ -1,5 +1,12 @@
 package game
 
+// Player inventory limits and unencumbered speed
+const (
+	playerSlots     = 20
+	playerMaxWeight = 20.0
+	playerSpeed     = 1.0
+)
+
 // Player represents a player object
 type Player struct {
 	Character
@@ -7,7 +14,7 @@ type Player struct {
 
 // NewPlayer creates player struct
 func NewPlayer(p Pos) *Player {
-	return &Player{
+	player := &Player{
 		Character: Character{
 			Entity: Entity{
 				Pos:    p,
@@ -15,15 +22,26 @@ func NewPlayer(p Pos) *Player {
 				Symbol: '@',
 			},
 			Hitpoints:    20,
+			MaxHitpoints: 20,
+			Mana:         10,
+			MaxMana:      10,
+			Gold:         20,
 			Damage:       5,
-			Speed:        1.0,
+			Speed:        playerSpeed,
 			ActionPoints: 0,
 			SightRange:   10,
+			Perception:   25,
+			MaxSlots:     playerSlots,
+			MaxWeight:    playerMaxWeight,
 		},
 	}
+	player.learnAbilities()
+
+	return player
 }
 
 // Move moves the player to a new position
 func (p *Player) Move(level *Level, to Pos) {
 	p.Pos = to
+	level.triggerTrap(&p.Character, to)
 }
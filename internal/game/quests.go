@@ -0,0 +1,215 @@
+package game
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+)
+
+// ObjectiveType declaration
+type ObjectiveType int
+
+// ObjectiveType enum declaration
+const (
+	KillObjective ObjectiveType = iota
+	CollectObjective
+	VisitObjective
+)
+
+// QuestState declaration
+type QuestState int
+
+// QuestState enum declaration
+const (
+	QuestActive QuestState = iota
+	QuestReady
+	QuestFinished
+)
+
+// Quest represents a task given to the player and their progress on it
+type Quest struct {
+	ID        string
+	Title     string
+	Objective ObjectiveType
+	Target    string
+	Count     int
+	Progress  int
+	State     QuestState
+}
+
+// Journal tracks the player's quests and deeds across every level
+type Journal struct {
+	Quests  []*Quest
+	Kills   map[string]int
+	Pickups map[string]int
+	Visited map[string]bool
+}
+
+const questsFile = "internal/game/data/quests.txt"
+
+// questCatalog holds every quest loaded from the quests file
+var questCatalog map[string]*Quest
+
+func loadQuests() {
+	file, err := os.Open(questsFile)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 5
+	csvReader.TrimLeadingSpace = true
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	objectives := map[string]ObjectiveType{
+		"kill":    KillObjective,
+		"collect": CollectObjective,
+		"visit":   VisitObjective,
+	}
+
+	questCatalog = make(map[string]*Quest)
+	for _, row := range rows {
+		objective, exists := objectives[row[2]]
+		if !exists {
+			panic("Unknown quest objective: " + row[2])
+		}
+
+		count, err := strconv.Atoi(row[4])
+		if err != nil {
+			panic(err)
+		}
+
+		questCatalog[row[0]] = &Quest{
+			ID:        row[0],
+			Title:     row[1],
+			Objective: objective,
+			Target:    row[3],
+			Count:     count,
+		}
+	}
+}
+
+func newJournal() *Journal {
+	return &Journal{
+		Quests:  make([]*Quest, 0),
+		Kills:   make(map[string]int),
+		Pickups: make(map[string]int),
+		Visited: make(map[string]bool),
+	}
+}
+
+// Quest returns the quest with the given id, or nil if it hasn't been started
+func (journal *Journal) Quest(id string) *Quest {
+	for _, quest := range journal.Quests {
+		if quest.ID == id {
+			return quest
+		}
+	}
+
+	return nil
+}
+
+// Describe returns a short summary of a quest's objective and progress
+func (quest *Quest) Describe() string {
+	switch quest.State {
+	case QuestReady:
+		return "Return for your reward"
+	case QuestFinished:
+		return "Finished"
+	}
+
+	switch quest.Objective {
+	case KillObjective:
+		return "Kill " + quest.Target + " (" + strconv.Itoa(quest.Progress) + "/" + strconv.Itoa(quest.Count) + ")"
+	case CollectObjective:
+		return "Collect " + quest.Target + " (" + strconv.Itoa(quest.Progress) + "/" + strconv.Itoa(quest.Count) + ")"
+	default:
+		return "Visit " + quest.Target
+	}
+}
+
+// startQuest adds a quest from the catalog to the journal
+func (level *Level) startQuest(id string) {
+	template, exists := questCatalog[id]
+	if !exists || level.Journal.Quest(id) != nil {
+		return
+	}
+
+	quest := *template
+	level.Journal.Quests = append(level.Journal.Quests, &quest)
+	level.addEvent(Event{Type: QuestUpdated, Text: "New quest: " + quest.Title})
+
+	// places already visited count straight away
+	if quest.Objective == VisitObjective && level.Journal.Visited[quest.Target] {
+		level.advanceQuest(&quest, quest.Count)
+	}
+}
+
+// finishQuest marks a quest as handed in
+func (level *Level) finishQuest(id string) {
+	quest := level.Journal.Quest(id)
+	if quest == nil || quest.State == QuestFinished {
+		return
+	}
+
+	quest.State = QuestFinished
+	level.addEvent(Event{Type: QuestUpdated, Text: "Quest finished: " + quest.Title})
+}
+
+func (level *Level) advanceQuest(quest *Quest, progress int) {
+	if quest.State != QuestActive {
+		return
+	}
+
+	quest.Progress += progress
+	if quest.Progress >= quest.Count {
+		quest.Progress = quest.Count
+		quest.State = QuestReady
+		level.addEvent(Event{Type: QuestUpdated, Text: "Quest objective complete: " + quest.Title})
+	}
+}
+
+func (level *Level) recordKill(name string) {
+	if level.Journal == nil {
+		return
+	}
+
+	level.Journal.Kills[name]++
+	for _, quest := range level.Journal.Quests {
+		if quest.Objective == KillObjective && quest.Target == name {
+			level.advanceQuest(quest, 1)
+		}
+	}
+}
+
+func (level *Level) recordPickup(name string) {
+	if level.Journal == nil {
+		return
+	}
+
+	level.Journal.Pickups[name]++
+	for _, quest := range level.Journal.Quests {
+		if quest.Objective == CollectObjective && quest.Target == name {
+			level.advanceQuest(quest, 1)
+		}
+	}
+}
+
+func (level *Level) recordVisit(name string) {
+	if level.Journal == nil || level.Journal.Visited[name] {
+		return
+	}
+
+	level.Journal.Visited[name] = true
+	for _, quest := range level.Journal.Quests {
+		if quest.Objective == VisitObjective && quest.Target == name {
+			level.advanceQuest(quest, quest.Count)
+		}
+	}
+}
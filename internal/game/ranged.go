@@ -0,0 +1,106 @@
+package game
+
+// LineOfFire returns the path a projectile takes from one position towards
+// another, stopping before walls and closed doors and at the first character
+// in the way
+func (level *Level) LineOfFire(from Pos, to Pos) []Pos {
+	path := make([]Pos, 0)
+
+	for _, pos := range bresenham(from, to) {
+		if pos == from {
+			continue
+		}
+		if !level.canSee(pos) {
+			break
+		}
+
+		path = append(path, pos)
+
+		_, isMonster := level.Monsters[pos]
+		if isMonster || level.Player.Pos == pos {
+			break
+		}
+	}
+
+	return path
+}
+
+// fire shoots the character's ranged weapon at target, returning false if
+// nothing was fired
+func (level *Level) fire(c *Character, target Pos) bool {
+	weapon := c.Weapon
+	if weapon == nil || weapon.Range == 0 {
+		level.message(c.Name + " has nothing to fire!")
+		return false
+	}
+
+	xDelta := target.X - c.X
+	yDelta := target.Y - c.Y
+	if xDelta*xDelta+yDelta*yDelta > weapon.Range*weapon.Range {
+		level.message("Target is out of range!")
+		return false
+	}
+
+	path := level.LineOfFire(c.Pos, target)
+	if len(path) == 0 {
+		level.message("No line of fire!")
+		return false
+	}
+
+	if !level.useAmmo(c, weapon) {
+		return false
+	}
+
+	end := path[len(path)-1]
+
+	if monster, exists := level.Monsters[end]; exists {
+		monster.Asleep = false
+		level.hit(c, &monster.Character, weapon.Power)
+		level.checkDeath(&monster.Character)
+	} else if level.Player.Pos == end && c != &level.Player.Character {
+		level.hit(c, &level.Player.Character, weapon.Power)
+		level.checkDeath(&level.Player.Character)
+	} else {
+		c.ActionPoints--
+		level.addEvent(Event{Type: Attacked, Actor: c, From: c.Pos, Pos: end, Text: c.Name + " missed"})
+	}
+
+	// thrown weapons land where they stop
+	if weapon.Thrown {
+		c.Weapon = nil
+		weapon.Pos = end
+		level.Items[end] = stackItems(level.Items[end], weapon)
+	}
+
+	return true
+}
+
+// useAmmo spends a shot of the weapon's ammunition or charges, returning false
+// if there are none left
+func (level *Level) useAmmo(c *Character, weapon *Item) bool {
+	if weapon.Thrown {
+		return true
+	}
+
+	if weapon.AmmoName == "" {
+		if weapon.Charges <= 0 {
+			level.message("The " + weapon.Name + " is out of charges!")
+			return false
+		}
+		weapon.Charges--
+		return true
+	}
+
+	for i, item := range c.Items {
+		if item.Type == Ammo && item.Name == weapon.AmmoName && item.Count > 0 {
+			item.Count--
+			if item.Count == 0 {
+				c.Items = append(c.Items[:i], c.Items[i+1:]...)
+			}
+			return true
+		}
+	}
+
+	level.message(c.Name + " is out of " + weapon.AmmoName + "!")
+	return false
+}
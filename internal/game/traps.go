@@ -0,0 +1,185 @@
+package game
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// TrapType declaration
+type TrapType int
+
+// TrapType enum declaration
+const (
+	SpikeTrap TrapType = iota
+	GasTrap
+	TeleportTrap
+	AlarmTrap
+)
+
+// Enum of trap overlay symbols, shown once a trap has been found
+const (
+	SpikeTrapTile    rune = '^'
+	GasTrapTile           = '~'
+	TeleportTrapTile      = '*'
+	AlarmTrapTile         = '!'
+)
+
+// Trap represents a hidden hazard on a tile
+type Trap struct {
+	Type   TrapType
+	Symbol rune
+	Name   string
+	Damage int
+	Hidden bool
+}
+
+// NewTrap creates a hidden trap of the given type
+func NewTrap(t TrapType) *Trap {
+	trap := &Trap{
+		Type:   t,
+		Hidden: true,
+	}
+
+	switch t {
+	case SpikeTrap:
+		trap.Symbol = SpikeTrapTile
+		trap.Name = "spike trap"
+		trap.Damage = 3
+	case GasTrap:
+		trap.Symbol = GasTrapTile
+		trap.Name = "poison gas trap"
+		trap.Damage = 2
+	case TeleportTrap:
+		trap.Symbol = TeleportTrapTile
+		trap.Name = "teleport trap"
+	case AlarmTrap:
+		trap.Symbol = AlarmTrapTile
+		trap.Name = "alarm trap"
+	}
+
+	return trap
+}
+
+// revealTrap shows a hidden trap by giving its tile the trap overlay
+func (level *Level) revealTrap(pos Pos) {
+	trap, exists := level.Traps[pos]
+	if !exists || !trap.Hidden {
+		return
+	}
+
+	trap.Hidden = false
+	level.Tiles[pos.Y][pos.X].OverlaySymbol = trap.Symbol
+}
+
+// spotTraps gives the player a chance to notice hidden traps nearby
+func (level *Level) spotTraps() {
+	player := level.Player
+
+	for pos, trap := range level.Traps {
+		if !trap.Hidden || !level.Tiles[pos.Y][pos.X].Visible {
+			continue
+		}
+
+		xDelta := pos.X - player.X
+		yDelta := pos.Y - player.Y
+		if xDelta*xDelta+yDelta*yDelta > 2*2 {
+			continue
+		}
+
+		if rand.Intn(100) < player.Perception {
+			level.revealTrap(pos)
+			level.addEvent(Event{Type: Discovered, Actor: &player.Character, Pos: pos, Text: player.Name + " spotted a " + trap.Name})
+		}
+	}
+}
+
+// triggerTrap springs the trap at pos, if any, on the character standing there
+func (level *Level) triggerTrap(c *Character, pos Pos) {
+	trap, exists := level.Traps[pos]
+	if !exists {
+		return
+	}
+
+	level.revealTrap(pos)
+	event := Event{Type: TrapTriggered, Actor: c, From: pos, Pos: pos}
+
+	switch trap.Type {
+	case SpikeTrap:
+		level.addEvent(event)
+		c.Hitpoints -= trap.Damage
+		level.recordDamage(nil, c, trap.Damage, false,
+			c.Name+" stepped on a "+trap.Name+" for "+strconv.Itoa(trap.Damage),
+			c.Name+" stepped on a "+trap.Name,
+			c.Name+" was killed by a "+trap.Name)
+		level.checkDeath(c)
+	case GasTrap:
+		event.Text = c.Name + " set off a " + trap.Name
+		level.addEvent(event)
+		for _, victim := range level.charactersNear(pos) {
+			victim.Hitpoints -= trap.Damage
+			level.recordDamage(nil, victim, trap.Damage, false,
+				victim.Name+" choked on gas for "+strconv.Itoa(trap.Damage),
+				victim.Name+" choked on gas",
+				victim.Name+" choked to death")
+			level.checkDeath(victim)
+		}
+	case TeleportTrap:
+		event.Text = c.Name + " was teleported away"
+		level.addEvent(event)
+		level.teleport(c)
+	case AlarmTrap:
+		event.Text = "An alarm rings out!"
+		level.addEvent(event)
+		for _, monster := range level.Monsters {
+			monster.Asleep = false
+		}
+	}
+}
+
+// charactersNear returns the player and monsters on or next to pos
+func (level *Level) charactersNear(pos Pos) []*Character {
+	characters := make([]*Character, 0)
+
+	positions := append(pos.adjacent(true), pos)
+	for _, p := range positions {
+		if level.Player.Pos == p {
+			characters = append(characters, &level.Player.Character)
+		}
+		if monster, exists := level.Monsters[p]; exists {
+			characters = append(characters, &monster.Character)
+		}
+	}
+
+	return characters
+}
+
+// teleport moves a character to a random free tile on the level
+func (level *Level) teleport(c *Character) {
+	free := make([]Pos, 0)
+	for y, row := range level.Tiles {
+		for x := range row {
+			pos := Pos{x, y}
+			if level.canWalk(pos) && pos != level.Player.Pos {
+				if _, trapped := level.Traps[pos]; !trapped {
+					free = append(free, pos)
+				}
+			}
+		}
+	}
+
+	if len(free) == 0 {
+		return
+	}
+
+	to := free[rand.Intn(len(free))]
+	if monster, exists := level.Monsters[c.Pos]; exists && &monster.Character == c {
+		delete(level.Monsters, c.Pos)
+		level.Monsters[to] = monster
+	}
+
+	level.addEvent(Event{Type: Moved, Actor: c, From: c.Pos, Pos: to})
+	c.Pos = to
+	if c == &level.Player.Character {
+		level.lineOfSight()
+	}
+}
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"math"
+	"time"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// how long each kind of animation plays for
+const (
+	moveDuration  = 120 * time.Millisecond
+	lungeDuration = 160 * time.Millisecond
+	flashDuration = 200 * time.Millisecond
+	frameDuration = 250 * time.Millisecond
+)
+
+// lungeDistance is how far into the next tile an attacker lunges, as a
+// fraction of a tile
+const lungeDistance = 0.4
+
+// spriteAnimation is what a character on screen is in the middle of doing
+type spriteAnimation struct {
+	pos     game.Pos
+	from    game.Pos
+	moved   time.Time
+	target  game.Pos
+	lunged  time.Time
+	flashed time.Time
+}
+
+// animateLevel starts animations for the last turn's events, tweening
+// characters that moved a tile and lunging and flashing those that fought.
+// Animations play out as frames are drawn so input is never held up waiting
+// for them.
+func (a *App) animateLevel(level *game.Level) {
+	now := time.Now()
+
+	// forget everyone when changing level
+	if a.animatedLevel != level {
+		a.animatedLevel = level
+		a.sprites = make(map[*game.Character]*spriteAnimation)
+	}
+
+	characters := make(map[game.Pos]*game.Character)
+	characters[level.Player.Pos] = &level.Player.Character
+	for pos, monster := range level.Monsters {
+		characters[pos] = &monster.Character
+	}
+	for pos, npc := range level.NPCs {
+		characters[pos] = &npc.Character
+	}
+
+	seen := make(map[*game.Character]bool)
+	for pos, c := range characters {
+		seen[c] = true
+		if _, exists := a.sprites[c]; !exists {
+			a.sprites[c] = &spriteAnimation{pos: pos, from: pos}
+		}
+	}
+
+	for c := range a.sprites {
+		if !seen[c] {
+			delete(a.sprites, c)
+		}
+	}
+
+	for _, event := range level.Events {
+		switch event.Type {
+		case game.Moved:
+			sprite, exists := a.sprites[event.Actor]
+			if !exists {
+				continue
+			}
+
+			// only slide between neighbouring tiles, anything further
+			// jumps. Several moves in a turn slide from where the turn
+			// started.
+			from := event.From
+			if sprite.moved == now {
+				from = sprite.from
+			}
+
+			sprite.pos = event.Pos
+			sprite.from = event.Pos
+			sprite.moved = time.Time{}
+			if from != event.Pos && isAdjacent(from, event.Pos) {
+				sprite.from = from
+				sprite.moved = now
+			}
+		case game.Attacked:
+			if sprite, exists := a.sprites[event.Actor]; exists && isAdjacent(event.From, event.Pos) && event.From != event.Pos {
+				sprite.target = event.Pos
+				sprite.lunged = now
+			}
+		case game.Damaged:
+			if sprite, exists := a.sprites[event.Target]; exists {
+				sprite.flashed = now
+			}
+		}
+	}
+
+	// anything moved without an event, like an edit, jumps to where it is
+	for pos, c := range characters {
+		if sprite := a.sprites[c]; sprite.pos != pos {
+			sprite.pos = pos
+			sprite.from = pos
+			sprite.moved = time.Time{}
+		}
+	}
+}
+
+// progress returns how far through an animation started at start is, from 0
+// to 1
+func progress(start time.Time, duration time.Duration, now time.Time) float64 {
+	elapsed := now.Sub(start)
+	if start.IsZero() || elapsed >= duration {
+		return 1
+	}
+
+	return float64(elapsed) / float64(duration)
+}
+
+// getSpriteRect returns where to draw a character, part way along any move or
+// lunge it is making
+func (a *App) getSpriteRect(c *game.Character) *sdl.Rect {
+	rect := a.getTileRect(c.X, c.Y)
+
+	sprite, exists := a.sprites[c]
+	if !exists {
+		return rect
+	}
+
+	now := time.Now()
+	tileSize := float64(a.getTileSize())
+	offsetX, offsetY := 0.0, 0.0
+
+	// ease out of the previous tile
+	if t := progress(sprite.moved, moveDuration, now); t < 1 {
+		remaining := (1 - t) * (1 - t)
+		offsetX += float64(sprite.from.X-sprite.pos.X) * remaining
+		offsetY += float64(sprite.from.Y-sprite.pos.Y) * remaining
+	}
+
+	// jab towards the target and back
+	if t := progress(sprite.lunged, lungeDuration, now); t < 1 {
+		reach := lungeDistance * (1 - math.Abs(2*t-1))
+		offsetX += float64(sprite.target.X-sprite.pos.X) * reach
+		offsetY += float64(sprite.target.Y-sprite.pos.Y) * reach
+	}
+
+	rect.X += int32(offsetX * tileSize)
+	rect.Y += int32(offsetY * tileSize)
+	return rect
+}
+
+// isAnimating reports whether any character is still moving, lunging or
+// flashing
+func (a *App) isAnimating() bool {
+	now := time.Now()
+	for _, sprite := range a.sprites {
+		if progress(sprite.moved, moveDuration, now) < 1 ||
+			progress(sprite.lunged, lungeDuration, now) < 1 ||
+			progress(sprite.flashed, flashDuration, now) < 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAnimationFrame returns which frame animated sprites are on, changing
+// every frameDuration
+func getAnimationFrame() int64 {
+	return time.Now().UnixNano() / int64(frameDuration)
+}
+
+// isFlashing reports whether a character was just hit
+func (a *App) isFlashing(c *game.Character) bool {
+	sprite, exists := a.sprites[c]
+	return exists && progress(sprite.flashed, flashDuration, time.Now()) < 1
+}
+
+// drawCharacter draws a character at its animated position, tinted red for a
+// moment after being hit
+func (a *App) drawCharacter(c *game.Character) {
+	if a.isFlashing(c) {
+		a.textureAtlas.SetColorMod(255, 64, 64)
+		defer a.textureAtlas.SetColorMod(255, 255, 255)
+	}
+
+	a.copy(a.textureAtlas, a.getSpriteSrcRect(c.Symbol), a.getSpriteRect(c))
+}
+
+// getSpriteSrcRect returns the atlas rect for a glyph, stepping through the
+// frames of animated sprites
+func (a *App) getSpriteSrcRect(symbol rune) *sdl.Rect {
+	frames := a.frameIndex[symbol]
+	if len(frames) > 1 {
+		frame := getAnimationFrame() % int64(len(frames))
+		return &frames[frame]
+	}
+
+	return &a.textureIndex[symbol][0]
+}
+
+// isAdjacent reports whether two tiles are next to each other
+func isAdjacent(p1 game.Pos, p2 game.Pos) bool {
+	dx, dy := p1.X-p2.X, p1.Y-p2.Y
+	return dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+}
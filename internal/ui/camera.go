@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"math"
+	"time"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// zoomLevels are the tile scales the map can be drawn at
+var zoomLevels = []float64{0.5, 0.75, 1, 1.5, 2}
+
+const defaultZoom = 2
+
+// cameraDeadzone is the most tiles the player can move from the center of the
+// screen before the camera follows
+const cameraDeadzone = 5
+
+// cameraSmoothing is how much of the way to its target the camera moves each
+// cameraStep, however many frames are drawn in that time
+const (
+	cameraSmoothing = 0.2
+	cameraStep      = time.Second / 60
+)
+
+func (a *App) getTileSize() int32 {
+	return int32(math.Round(spriteHeight * zoomLevels[a.zoom]))
+}
+
+// getMapOrigin returns where the top left corner of the map is drawn,
+// including any screen shake
+func (a *App) getMapOrigin() (int32, int32) {
+	tileSize := float64(a.getTileSize())
+	originX := float64(a.width)/2 - (a.cameraX+0.5)*tileSize
+	originY := float64(a.height)/2 - (a.cameraY+0.5)*tileSize
+
+	return int32(math.Floor(originX)) + a.shakeX, int32(math.Floor(originY)) + a.shakeY
+}
+
+// getTileRect returns where a map tile is drawn on screen
+func (a *App) getTileRect(x int, y int) *sdl.Rect {
+	tileSize := a.getTileSize()
+	originX, originY := a.getMapOrigin()
+
+	return &sdl.Rect{
+		X: originX + int32(x)*tileSize,
+		Y: originY + int32(y)*tileSize,
+		W: tileSize,
+		H: tileSize,
+	}
+}
+
+func (a *App) mouseToTile(mx int32, my int32) (game.Pos, bool) {
+	tileSize := a.getTileSize()
+	originX, originY := a.getMapOrigin()
+
+	x := mx - originX
+	y := my - originY
+	if x < 0 || y < 0 {
+		return game.Pos{}, false
+	}
+
+	pos := game.Pos{X: int(x / tileSize), Y: int(y / tileSize)}
+	return pos, a.loadedLevel.InRange(pos)
+}
+
+// setCamera keeps the player near the center of the screen, gliding the
+// camera towards them rather than jumping a tile at a time
+func (a *App) setCamera() {
+	player := a.loadedLevel.Player
+
+	// start centered on the player when a level is first shown
+	snap := false
+	if a.cameraLevel != a.loadedLevel {
+		a.cameraLevel = a.loadedLevel
+		a.centerX = player.X
+		a.centerY = player.Y
+		snap = true
+	}
+
+	deadzoneX, deadzoneY := a.getCameraDeadzone()
+	if player.X > a.centerX+deadzoneX {
+		a.centerX = player.X - deadzoneX
+	} else if player.X < a.centerX-deadzoneX {
+		a.centerX = player.X + deadzoneX
+	}
+
+	if player.Y > a.centerY+deadzoneY {
+		a.centerY = player.Y - deadzoneY
+	} else if player.Y < a.centerY-deadzoneY {
+		a.centerY = player.Y + deadzoneY
+	}
+
+	now := time.Now()
+	steps := float64(now.Sub(a.cameraMoved)) / float64(cameraStep)
+	a.cameraMoved = now
+
+	targetX, targetY := a.clampCamera(float64(a.centerX), float64(a.centerY))
+	if snap {
+		a.cameraX, a.cameraY = targetX, targetY
+		return
+	}
+
+	t := 1 - math.Pow(1-cameraSmoothing, steps)
+	a.cameraX = approach(a.cameraX, targetX, t)
+	a.cameraY = approach(a.cameraY, targetY, t)
+}
+
+// getCameraDeadzone returns how far the player can move from the center
+// across and down before the camera follows, shrunk so the player stays on
+// screen when few tiles fit
+func (a *App) getCameraDeadzone() (int, int) {
+	tileSize := a.getTileSize()
+	deadzone := func(screenSize int32) int {
+		d := int(screenSize/tileSize)/2 - 1
+		if d > cameraDeadzone {
+			d = cameraDeadzone
+		}
+		if d < 0 {
+			d = 0
+		}
+		return d
+	}
+
+	return deadzone(a.width), deadzone(a.height)
+}
+
+// isCameraMoving reports whether the camera is still easing towards its
+// target
+func (a *App) isCameraMoving() bool {
+	if a.state == editorState || a.cameraLevel != a.loadedLevel {
+		return false
+	}
+
+	targetX, targetY := a.clampCamera(float64(a.centerX), float64(a.centerY))
+	return a.cameraX != targetX || a.cameraY != targetY
+}
+
+// approach moves a camera coordinate t of the way towards its target
+func approach(from float64, to float64, t float64) float64 {
+	diff := to - from
+	if math.Abs(diff) < 0.01 {
+		return to
+	}
+
+	return from + diff*t
+}
+
+// clampCamera keeps the view over the map, centering maps smaller than the
+// screen rather than showing empty space past their edges
+func (a *App) clampCamera(x float64, y float64) (float64, float64) {
+	tileSize := float64(a.getTileSize())
+	mapWidth := int(a.getLevelWidth())
+	mapHeight := len(a.loadedLevel.Tiles)
+
+	clamp := func(center float64, mapSize int, viewSize float64) float64 {
+		if float64(mapSize) <= viewSize {
+			return float64(mapSize-1) / 2
+		}
+
+		low := viewSize/2 - 0.5
+		high := float64(mapSize) - viewSize/2 - 0.5
+		return math.Max(low, math.Min(high, center))
+	}
+
+	return clamp(x, mapWidth, float64(a.width)/tileSize), clamp(y, mapHeight, float64(a.height)/tileSize)
+}
+
+// zoomCamera steps the map zoom in or out, keeping it within the zoom levels
+func (a *App) zoomCamera(step int) {
+	a.zoom += step
+	if a.zoom < 0 {
+		a.zoom = 0
+	}
+	if a.zoom >= len(zoomLevels) {
+		a.zoom = len(zoomLevels) - 1
+	}
+}
@@ -3,6 +3,14 @@ package ui
 const spriteHeight = 32
 const itemSizeRatio = 0.033
 
+// inventoryRows is how many rows of the inventory grid are shown at once, and
+// inventoryColumns the most items across it. A full pack is more than fits,
+// so the grid scrolls.
+const (
+	inventoryRows    = 2
+	inventoryColumns = 8
+)
+
 func (a *App) getItemSize() int32 {
 	return int32(itemSizeRatio * float32(a.width))
 }
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func (a *App) toggleControls() {
+	if a.state == mainState {
+		a.state = controlsState
+	} else if a.state == controlsState {
+		a.state = mainState
+	}
+}
+
+func (a *App) drawControls() {
+	inventoryRect := a.getInventoryBackdropRect()
+	a.copy(a.inventoryBackground, nil, inventoryRect)
+
+	_, lineHeight, _ := a.smallFont.SizeUTF8("A")
+	perColumn := int(inventoryRect.H-16) / lineHeight
+	columnWidth := (inventoryRect.W - 16) / 2
+
+	tex := a.stringToTexture("Controls", mediumFont, textColor)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: inventoryRect.X + (inventoryRect.W-w)/2, Y: inventoryRect.Y + 8, W: w, H: h})
+		perColumn = int(inventoryRect.H-24-h) / lineHeight
+	}
+
+	for i, info := range keyActions[actionUp:] {
+		keys := strings.Join(a.keysFor(actionUp+keyAction(i)), ", ")
+		if keys == "" {
+			keys = "unbound"
+		}
+
+		tex := a.stringToTexture(info.description+": "+keys, smallFont, textColor)
+		_, _, w, lh, err := tex.Query()
+		if err != nil {
+			continue
+		}
+
+		column := int32(i / perColumn)
+		row := int32(i % perColumn)
+		a.copy(tex, nil, &sdl.Rect{
+			X: inventoryRect.X + 8 + column*columnWidth,
+			Y: inventoryRect.Y + inventoryRect.H - 8 - int32(perColumn)*int32(lineHeight) + row*int32(lineHeight),
+			W: w,
+			H: lh,
+		})
+	}
+}
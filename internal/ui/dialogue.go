@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// checkForChoice returns the index of the reply under the mouse, or -1
+func (a *App) checkForChoice(mx int32, my int32) int {
+	mouseRect := a.getMouseRect(mx, my)
+
+	for i := range a.loadedLevel.Choices() {
+		if a.getDialogueRowRect(i).HasIntersection(mouseRect) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// moveChoiceCursor moves the highlighted reply up or down, wrapping around
+func (a *App) moveChoiceCursor(delta int) {
+	choices := len(a.loadedLevel.Choices())
+	if choices == 0 {
+		return
+	}
+
+	a.choiceCursor = (a.choiceCursor + delta + choices) % choices
+}
+
+// wrapText splits s into lines no wider than width when drawn in the small font
+func (a *App) wrapText(s string, width int32) []string {
+	lines := make([]string, 0)
+
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+
+		w, _, err := a.smallFont.SizeUTF8(next)
+		if err == nil && int32(w) > width && line != "" {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func (a *App) drawDialogue() {
+	conversation := a.loadedLevel.Talking
+	if conversation == nil {
+		return
+	}
+
+	dialogueRect := a.getDialogueRect()
+	a.copy(a.inventoryBackground, nil, dialogueRect)
+
+	white := sdl.Color{R: 255, G: 255, B: 255}
+	lines := append([]string{conversation.NPC.Name + ":"}, a.wrapText(conversation.Node.Text, dialogueRect.W-16)...)
+
+	y := dialogueRect.Y + 8
+	for _, line := range lines {
+		tex := a.stringToTexture(line, smallFont, white)
+		_, _, w, h, err := tex.Query()
+		if err == nil {
+			a.copy(tex, nil, &sdl.Rect{X: dialogueRect.X + 8, Y: y, W: w, H: h})
+			y += h
+		}
+	}
+
+	for i, choice := range a.loadedLevel.Choices() {
+		rowRect := a.getDialogueRowRect(i)
+		if i == a.choiceCursor {
+			a.copy(a.eventBackground, nil, rowRect)
+		}
+
+		tex := a.stringToTexture(strconv.Itoa(i+1)+". "+choice.Text, smallFont, white)
+		_, _, w, h, err := tex.Query()
+		if err == nil {
+			a.copy(tex, nil, &sdl.Rect{X: rowRect.X, Y: rowRect.Y + (rowRect.H-h)/2, W: w, H: h})
+		}
+	}
+}
@@ -2,17 +2,24 @@ package ui
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
-	"github.com/chumnend/dungeon-rpg/internal/game"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 func (a *App) draw() {
+	start := time.Now()
+	a.startFrame()
 	a.renderer.Clear()
-	a.r.Seed(1)
 
-	// move the camera with the player
-	a.setCamera()
+	// move the camera with the player, the editor pans freely
+	if a.state != editorState {
+		a.setCamera()
+	} else {
+		a.cameraX, a.cameraY = float64(a.centerX), float64(a.centerY)
+	}
+	a.updateShake()
 
 	// draw floor tiles
 	a.drawFloor()
@@ -23,139 +30,113 @@ func (a *App) draw() {
 	// draw monsters
 	a.drawMonsters()
 
+	// draw npcs
+	a.drawNPCs()
+
 	// draw items on ground
 	a.drawFloorItems()
 
+	// draw combat text, particles and the flash when the player is hurt
+	a.drawEffects()
+	a.drawDamageFlash()
+
 	// draw items on pickup bar
 	a.drawPickupBarItems()
 
 	// draw event log
 	a.drawEventLog()
 
+	// draw ability hotbar, player stats and the minimap
+	if a.state != editorState {
+		a.drawHotbar()
+		a.drawMinimap()
+	}
+
 	// draw the inventory screen
 	if a.state == inventoryState {
 		a.drawInventory()
 	}
 
-	a.renderer.Present()
-}
+	// draw the trade screen
+	if a.state == tradeState {
+		a.drawTrade()
+	}
 
-func (a *App) setCamera() {
-	if a.centerX == -1 && a.centerY == -1 {
-		a.centerX = a.loadedLevel.Player.X
-		a.centerY = a.loadedLevel.Player.Y
+	// draw the conversation with an npc
+	if a.state == dialogueState {
+		a.drawDialogue()
 	}
 
-	limit := 5
-	if a.loadedLevel.Player.X > a.centerX+limit {
-		diff := a.loadedLevel.Player.X - (a.centerX + limit)
-		a.centerX += diff
-	} else if a.loadedLevel.Player.X < a.centerX-limit {
-		diff := (a.centerX - limit) - a.loadedLevel.Player.X
-		a.centerX -= diff
+	// draw the list of key bindings
+	if a.state == controlsState {
+		a.drawControls()
 	}
 
-	if a.loadedLevel.Player.Y > a.centerY+limit {
-		diff := a.loadedLevel.Player.Y - (a.centerY + limit)
-		a.centerY += diff
-	} else if a.loadedLevel.Player.Y < a.centerY-limit {
-		diff := (a.centerY - limit) - a.loadedLevel.Player.Y
-		a.centerY -= diff
+	// draw the explored map over everything else
+	if a.state == mapState {
+		a.drawMap()
 	}
-}
 
-func (a *App) drawFloor() {
-	offsetX := (a.width / 2) - int32(a.centerX*spriteHeight)
-	offsetY := (a.height / 2) - int32(a.centerY*spriteHeight)
+	// draw the message history
+	if a.state == logState {
+		a.drawLog()
+	}
 
-	for y, row := range a.loadedLevel.Tiles {
-		for x, tile := range row {
-			if tile.Symbol == game.EmptyTile {
-				continue
-			}
+	// draw the quest journal
+	if a.state == journalState {
+		a.drawJournal()
+	}
 
-			srcRects := a.textureIndex[tile.Symbol]
-			srcRect := srcRects[a.r.Intn(len(srcRects))]
-
-			if tile.Visible || tile.Seen {
-				destRect := sdl.Rect{
-					X: int32(x*spriteHeight) + offsetX,
-					Y: int32(y*spriteHeight) + offsetY,
-					W: spriteHeight,
-					H: spriteHeight,
-				}
-
-				pos := game.Pos{X: x, Y: y}
-				if a.loadedLevel.Debug[pos] {
-					a.textureAtlas.SetColorMod(128, 0, 0)
-				} else if tile.Seen && !tile.Visible {
-					a.textureAtlas.SetColorMod(128, 128, 128)
-				} else {
-					a.textureAtlas.SetColorMod(255, 255, 255)
-				}
-
-				a.renderer.Copy(a.textureAtlas, &srcRect, &destRect)
-
-				if tile.OverlaySymbol != game.EmptyTile {
-					srcRect = a.textureIndex[tile.OverlaySymbol][0]
-					a.renderer.Copy(a.textureAtlas, &srcRect, &destRect)
-				}
+	// draw the targeting path
+	if a.state == targetingState {
+		a.drawTargeting()
+	}
 
-			}
-		}
+	// draw the level editor
+	if a.state == editorState {
+		a.drawEditor()
 	}
 
-	a.textureAtlas.SetColorMod(255, 255, 255)
+	// draw the inspected item and the tooltip of the hovered one on top
+	a.drawInspection()
+	a.drawHoveredTooltip()
+
+	// draw the frame timings over everything
+	a.drawStats()
+
+	a.endFrame(start)
+	a.renderer.Present()
+	a.dirty = false
 }
 
 func (a *App) drawPlayer() {
-	offsetX := (a.width / 2) - int32(a.centerX*spriteHeight)
-	offsetY := (a.height / 2) - int32(a.centerY*spriteHeight)
-
-	playerSrcRect := a.textureIndex[a.loadedLevel.Player.Symbol][0]
-	playerDestRect := sdl.Rect{
-		X: int32(a.loadedLevel.Player.X*spriteHeight) + offsetX,
-		Y: int32(a.loadedLevel.Player.Y*spriteHeight) + offsetY,
-		W: spriteHeight,
-		H: spriteHeight,
-	}
-	a.renderer.Copy(a.textureAtlas, &playerSrcRect, &playerDestRect)
+	a.drawCharacter(&a.loadedLevel.Player.Character)
 }
 
 func (a *App) drawMonsters() {
-	offsetX := (a.width / 2) - int32(a.centerX*spriteHeight)
-	offsetY := (a.height / 2) - int32(a.centerY*spriteHeight)
-
 	for pos, monster := range a.loadedLevel.Monsters {
-		if a.loadedLevel.Tiles[pos.Y][pos.X].Visible {
-			monsterSrcRect := a.textureIndex[monster.Symbol][0]
-			monsterDestRect := sdl.Rect{
-				X: int32(pos.X)*spriteHeight + offsetX,
-				Y: int32(pos.Y)*spriteHeight + offsetY,
-				W: spriteHeight,
-				H: spriteHeight,
-			}
-			a.renderer.Copy(a.textureAtlas, &monsterSrcRect, &monsterDestRect)
+		if a.loadedLevel.Tiles[pos.Y][pos.X].Visible || a.state == editorState {
+			a.drawCharacter(&monster.Character)
 		}
 	}
 
 }
 
-func (a *App) drawFloorItems() {
-	offsetX := (a.width / 2) - int32(a.centerX*spriteHeight)
-	offsetY := (a.height / 2) - int32(a.centerY*spriteHeight)
+func (a *App) drawNPCs() {
+	for pos, npc := range a.loadedLevel.NPCs {
+		if a.loadedLevel.Tiles[pos.Y][pos.X].Visible || a.state == editorState {
+			a.drawCharacter(&npc.Character)
+		}
+	}
+}
 
+func (a *App) drawFloorItems() {
 	for pos, items := range a.loadedLevel.Items {
-		if a.loadedLevel.Tiles[pos.Y][pos.X].Visible {
+		if a.loadedLevel.Tiles[pos.Y][pos.X].Visible || a.state == editorState {
 			for _, item := range items {
 				itemSrcRect := a.textureIndex[item.Symbol][0]
-				itemDestRect := sdl.Rect{
-					X: int32(pos.X)*spriteHeight + offsetX,
-					Y: int32(pos.Y)*spriteHeight + offsetY,
-					W: spriteHeight,
-					H: spriteHeight,
-				}
-				a.renderer.Copy(a.textureAtlas, &itemSrcRect, &itemDestRect)
+				itemDestRect := a.getTileRect(pos.X, pos.Y)
+				a.copy(a.textureAtlas, &itemSrcRect, itemDestRect)
 			}
 		}
 	}
@@ -171,7 +152,7 @@ func (a *App) drawPickupBarItems() {
 		return
 	}
 
-	a.renderer.Copy(a.inventoryBackground, nil, &sdl.Rect{
+	a.copy(a.inventoryBackground, nil, &sdl.Rect{
 		X: inventoryStart,
 		Y: a.height - itemSize,
 		W: inventoryWIdth,
@@ -181,39 +162,9 @@ func (a *App) drawPickupBarItems() {
 	for i, item := range items {
 		itemSrcRect := &a.textureIndex[item.Symbol][0]
 		itemDestRect := a.getPickupItemRect(i)
-		a.renderer.Copy(a.textureAtlas, itemSrcRect, itemDestRect)
-	}
-}
-
-func (a *App) drawEventLog() {
-	textStart := int32(float64(a.height) * 0.75)
-	a.renderer.Copy(a.eventBackground, nil, &sdl.Rect{
-		X: 0,
-		Y: textStart,
-		W: int32(float64(a.width) * 0.25),
-		H: int32(float64(a.height) * 0.75),
-	})
-
-	_, fontSizeY, _ := a.smallFont.SizeUTF8("A")
-
-	i := a.loadedLevel.EventPos
-	count := 0
-	for {
-		event := a.loadedLevel.Events[i]
-		if event != "" {
-			tex := a.stringToTexture(event, smallFont, sdl.Color{R: 255, G: 0, B: 0})
-			_, _, w, h, err := tex.Query()
-			if err != nil {
-				fmt.Println("Problem loading event: " + event)
-			}
-			a.renderer.Copy(tex, nil, &sdl.Rect{X: 0, Y: int32(count*fontSizeY) + textStart, W: w, H: h})
-		}
-
-		i = (i + 1) % (len(a.loadedLevel.Events))
-		count++
-
-		if i == a.loadedLevel.EventPos {
-			break
+		a.copy(a.textureAtlas, itemSrcRect, itemDestRect)
+		if item.Count > 1 {
+			a.drawItemCount(item.Count, itemDestRect)
 		}
 	}
 }
@@ -221,32 +172,41 @@ func (a *App) drawEventLog() {
 func (a *App) drawInventory() {
 	// draw inventory backdrop
 	inventoryRect := a.getInventoryBackdropRect()
-	a.renderer.Copy(a.inventoryBackground, nil, inventoryRect)
+	a.copy(a.inventoryBackground, nil, inventoryRect)
 
 	// draw equipment bar
 	weaponRect := a.getWeaponSlotRect()
-	a.renderer.Copy(a.slotBackground, nil, weaponRect)
+	a.copy(a.slotBackground, nil, weaponRect)
 	if a.loadedLevel.Player.Weapon != nil {
-		a.renderer.Copy(a.textureAtlas, &a.textureIndex[a.loadedLevel.Player.Weapon.Symbol][0], weaponRect)
+		a.copy(a.textureAtlas, &a.textureIndex[a.loadedLevel.Player.Weapon.Symbol][0], weaponRect)
 	}
 
 	armorRect := a.getArmorSlotRect()
-	a.renderer.Copy(a.slotBackground, nil, armorRect)
+	a.copy(a.slotBackground, nil, armorRect)
 	if a.loadedLevel.Player.Armor != nil {
-		a.renderer.Copy(a.textureAtlas, &a.textureIndex[a.loadedLevel.Player.Armor.Symbol][0], armorRect)
+		a.copy(a.textureAtlas, &a.textureIndex[a.loadedLevel.Player.Armor.Symbol][0], armorRect)
 	}
 
 	// draw player in inventory
 	playerSrcRect := a.textureIndex[a.loadedLevel.Player.Symbol][0]
-	a.renderer.Copy(a.textureAtlas, &playerSrcRect, &sdl.Rect{
+	a.copy(a.textureAtlas, &playerSrcRect, &sdl.Rect{
 		X: inventoryRect.X + inventoryRect.X/2,
 		Y: inventoryRect.Y + inventoryRect.Y/2,
 		W: inventoryRect.W / 2,
 		H: inventoryRect.H / 2,
 	})
 
+	// draw the inventory grid
+	player := a.loadedLevel.Player
+	for i := 0; i < player.MaxSlots || i < len(player.Items); i++ {
+		slotRect, visible := a.getInventoryItemRect(i)
+		if visible {
+			a.copy(a.eventBackground, nil, &sdl.Rect{X: slotRect.X + 1, Y: slotRect.Y + 1, W: slotRect.W - 2, H: slotRect.H - 2})
+		}
+	}
+
 	// draw items in inventory
-	for i, item := range a.loadedLevel.Player.Items {
+	for i, item := range player.Items {
 		itemSrcRect := &a.textureIndex[item.Symbol][0]
 
 		if item == a.dragged {
@@ -258,10 +218,34 @@ func (a *App) drawInventory() {
 				W: itemSize,
 				H: itemSize,
 			}
-			a.renderer.Copy(a.textureAtlas, itemSrcRect, itemDestRect)
-		} else {
-			itemDestRect := a.getInventoryItemRect(i)
-			a.renderer.Copy(a.textureAtlas, itemSrcRect, itemDestRect)
+			a.copy(a.textureAtlas, itemSrcRect, itemDestRect)
+		} else if itemDestRect, visible := a.getInventoryItemRect(i); visible {
+			a.copy(a.textureAtlas, itemSrcRect, itemDestRect)
+			if item.Count > 1 {
+				a.drawItemCount(item.Count, itemDestRect)
+			}
 		}
 	}
+
+	// draw slot letters, the keyboard cursor and action prompt
+	a.drawInventoryKeys()
+
+	// draw carrying capacity
+	capacity := fmt.Sprintf("Slots %d/%d  Weight %.1f/%.1f", len(player.Items), player.MaxSlots, player.Weight(), player.MaxWeight)
+	color := sdl.Color{R: 255, G: 255, B: 255}
+	if player.Encumbered() {
+		capacity += "  (encumbered)"
+	}
+	tex := a.stringToTexture(capacity, smallFont, color)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: inventoryRect.X + 8, Y: inventoryRect.Y + 8, W: w, H: h})
+	}
+}
+
+// drawItemCount draws the size of a stack in the corner of its slot
+func (a *App) drawItemCount(count int, itemRect *sdl.Rect) {
+	s := strconv.Itoa(count)
+	w, h := a.getGlyphTextSize(s, smallFont)
+	a.drawGlyphText(s, smallFont, textColor, 255, itemRect.X+itemRect.W-w, itemRect.Y+itemRect.H-h)
 }
@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// portalGlyph is the palette glyph used to place portals, drawn with the
+// down stair sprite
+const portalGlyph = '>'
+
+type paletteEntry struct {
+	glyph  rune
+	sprite rune
+}
+
+var editorPalette = []paletteEntry{
+	{glyph: game.StoneTile, sprite: game.StoneTile},
+	{glyph: game.DirtTile, sprite: game.DirtTile},
+	{glyph: game.EmptyTile, sprite: game.EmptyTile},
+	{glyph: game.ClosedDoorTile, sprite: game.ClosedDoorTile},
+	{glyph: game.OpenedDoorTile, sprite: game.OpenedDoorTile},
+	{glyph: game.LockedDoorTile, sprite: game.LockedDoorTile},
+	{glyph: game.SecretDoorTile, sprite: game.ClosedDoorTile},
+	{glyph: game.UpStairTile, sprite: game.UpStairTile},
+	{glyph: game.DownStairTile, sprite: game.DownStairTile},
+	{glyph: 'R', sprite: 'R'},
+	{glyph: 'S', sprite: 'S'},
+	{glyph: 's', sprite: 's'},
+	{glyph: 'h', sprite: 'h'},
+	{glyph: 'k', sprite: 'k'},
+	{glyph: 'b', sprite: 'b'},
+	{glyph: 'a', sprite: 'a'},
+	{glyph: 't', sprite: 't'},
+	{glyph: 'w', sprite: 'w'},
+	{glyph: 'p', sprite: 'p'},
+	{glyph: game.SpikeTrapTile, sprite: game.SpikeTrapTile},
+	{glyph: game.GasTrapTile, sprite: game.GasTrapTile},
+	{glyph: game.TeleportTrapTile, sprite: game.TeleportTrapTile},
+	{glyph: game.AlarmTrapTile, sprite: game.AlarmTrapTile},
+	{glyph: portalGlyph, sprite: game.DownStairTile},
+}
+
+// cellChange records a single tile edit so it can be undone and redone
+type cellChange struct {
+	pos    game.Pos
+	before game.Cell
+	after  game.Cell
+}
+
+// levelEditor holds the state of the level editor. The editor works on a
+// copy of the level loaded from its map file, the level being played is put
+// back when it closes.
+type levelEditor struct {
+	name         string
+	playing      *game.Level
+	selected     int
+	portalTarget int
+	painting     bool
+	erasing      bool
+	stroke       []cellChange
+	undoStack    [][]cellChange
+	redoStack    [][]cellChange
+}
+
+func (a *App) toggleEditor() {
+	if a.state == mainState {
+		name := a.game.LevelName(a.loadedLevel)
+		a.editor = levelEditor{name: name, playing: a.loadedLevel}
+		a.state = editorState
+		a.showLevel(a.game.EditLevel(name))
+	} else if a.state == editorState {
+		a.endStroke()
+		a.state = mainState
+		a.showLevel(a.editor.playing)
+		a.editor.playing = nil
+	}
+}
+
+// showLevel swaps the level on screen without moving the camera
+func (a *App) showLevel(level *game.Level) {
+	a.loadedLevel = level
+	a.cameraLevel = level
+	a.updateFloor(level)
+}
+
+func (a *App) portalTargets() []string {
+	current := a.editor.name
+
+	targets := make([]string, 0)
+	for _, name := range a.game.LevelNames() {
+		if name != current {
+			targets = append(targets, name)
+		}
+	}
+
+	return targets
+}
+
+func (a *App) cyclePortalTarget() {
+	targets := a.portalTargets()
+	if len(targets) == 0 {
+		return
+	}
+
+	a.editor.portalTarget = (a.editor.portalTarget + 1) % len(targets)
+}
+
+func (a *App) startStroke(mx int32, my int32, erasing bool) {
+	a.editor.painting = true
+	a.editor.erasing = erasing
+	a.editor.stroke = make([]cellChange, 0)
+	a.applyStroke(mx, my)
+}
+
+func (a *App) applyStroke(mx int32, my int32) {
+	if !a.editor.painting {
+		return
+	}
+
+	pos, ok := a.mouseToTile(mx, my)
+	if !ok {
+		return
+	}
+
+	// only edit each cell once per stroke so dragging doesn't stack items
+	for _, change := range a.editor.stroke {
+		if change.pos == pos {
+			return
+		}
+	}
+
+	level := a.loadedLevel
+	before := level.Cell(pos)
+
+	if a.editor.erasing {
+		level.Erase(pos)
+	} else {
+		glyph := editorPalette[a.editor.selected].glyph
+		if glyph == portalGlyph {
+			targets := a.portalTargets()
+			if len(targets) == 0 {
+				return
+			}
+			a.game.SetPortal(level, pos, targets[a.editor.portalTarget%len(targets)])
+		} else if !level.Paint(pos, glyph) {
+			return
+		}
+	}
+
+	a.editor.stroke = append(a.editor.stroke, cellChange{
+		pos:    pos,
+		before: before,
+		after:  level.Cell(pos),
+	})
+	a.markFloorStale(pos.X, pos.Y)
+}
+
+func (a *App) endStroke() {
+	a.editor.painting = false
+
+	if len(a.editor.stroke) > 0 {
+		a.editor.undoStack = append(a.editor.undoStack, a.editor.stroke)
+		a.editor.redoStack = nil
+	}
+
+	a.editor.stroke = nil
+}
+
+func (a *App) undoEdit() {
+	if len(a.editor.undoStack) == 0 {
+		return
+	}
+
+	last := len(a.editor.undoStack) - 1
+	stroke := a.editor.undoStack[last]
+	a.editor.undoStack = a.editor.undoStack[:last]
+
+	for i := len(stroke) - 1; i >= 0; i-- {
+		a.loadedLevel.SetCell(stroke[i].pos, stroke[i].before)
+		a.markFloorStale(stroke[i].pos.X, stroke[i].pos.Y)
+	}
+
+	a.editor.redoStack = append(a.editor.redoStack, stroke)
+}
+
+func (a *App) redoEdit() {
+	if len(a.editor.redoStack) == 0 {
+		return
+	}
+
+	last := len(a.editor.redoStack) - 1
+	stroke := a.editor.redoStack[last]
+	a.editor.redoStack = a.editor.redoStack[:last]
+
+	for _, change := range stroke {
+		a.loadedLevel.SetCell(change.pos, change.after)
+		a.markFloorStale(change.pos.X, change.pos.Y)
+	}
+
+	a.editor.undoStack = append(a.editor.undoStack, stroke)
+}
+
+func (a *App) saveLevel() {
+	err := a.game.SaveLevel(a.editor.name, a.loadedLevel)
+	if err == nil {
+		err = a.game.SaveWorld()
+	}
+
+	if err != nil {
+		a.addMessage("Failed to save: " + err.Error())
+		return
+	}
+
+	a.addMessage("Saved " + a.editor.name)
+}
+
+func (a *App) checkForPaletteSlot(mx int32, my int32) int {
+	mouseRect := a.getMouseRect(mx, my)
+
+	for i := range editorPalette {
+		slotRect := a.getPaletteSlotRect(i)
+		if slotRect.HasIntersection(mouseRect) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (a *App) drawEditor() {
+	// mark portals
+	for pos := range a.loadedLevel.Portals {
+		a.copy(a.portalHighlight, nil, a.getTileRect(pos.X, pos.Y))
+	}
+
+	// show hidden traps
+	for pos, trap := range a.loadedLevel.Traps {
+		if srcRects, exists := a.textureIndex[trap.Symbol]; exists && trap.Hidden {
+			a.copy(a.textureAtlas, &srcRects[0], a.getTileRect(pos.X, pos.Y))
+		}
+	}
+
+	// outline the hovered tile
+	mx, my, _ := sdl.GetMouseState()
+	if pos, ok := a.mouseToTile(mx, my); ok {
+		a.renderer.SetDrawColor(255, 255, 255, 255)
+		a.drawRect(a.getTileRect(pos.X, pos.Y))
+		a.renderer.SetDrawColor(0, 0, 0, 255)
+	}
+
+	// draw the palette
+	for i, entry := range editorPalette {
+		slotRect := a.getPaletteSlotRect(i)
+		if i == a.editor.selected {
+			a.copy(a.inventoryBackground, nil, &sdl.Rect{
+				X: slotRect.X - 4,
+				Y: slotRect.Y - 4,
+				W: slotRect.W + 8,
+				H: slotRect.H + 8,
+			})
+		}
+
+		a.copy(a.slotBackground, nil, slotRect)
+		if srcRects, exists := a.textureIndex[entry.sprite]; exists {
+			a.copy(a.textureAtlas, &srcRects[0], slotRect)
+		}
+		if entry.glyph == portalGlyph {
+			a.copy(a.portalHighlight, nil, slotRect)
+		}
+	}
+
+	// draw editor status
+	status := "Editing " + a.editor.name
+	if editorPalette[a.editor.selected].glyph == portalGlyph {
+		targets := a.portalTargets()
+		if len(targets) > 0 {
+			status += " - portal to " + targets[a.editor.portalTarget%len(targets)] + " (Tab to change)"
+		}
+	}
+
+	tex := a.stringToTexture(status, smallFont, sdl.Color{R: 255, G: 255, B: 255})
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		paletteRect := a.getPaletteSlotRect(len(editorPalette) - 1)
+		paletteRect.X = a.getPaletteSlotRect(0).X
+		a.copy(tex, nil, &sdl.Rect{X: paletteRect.X, Y: paletteRect.Y + paletteRect.H + 8, W: w, H: h})
+	}
+}
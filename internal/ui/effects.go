@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// how long each kind of effect lasts
+const (
+	floatingTextDuration = 900 * time.Millisecond
+	damageFlashDuration  = 300 * time.Millisecond
+	shakeDuration        = 300 * time.Millisecond
+	particleDuration     = 700 * time.Millisecond
+)
+
+// how far floating text rises and the screen shakes, in tiles
+const (
+	floatingTextRise = 1.0
+	shakeDistance    = 0.2
+)
+
+// particles thrown out when something dies, and how fast they go in tiles a
+// second
+const (
+	deathParticles = 16
+	particleSpeed  = 2.5
+	particleSize   = 0.1
+	particleFall   = 4.0
+)
+
+var (
+	damageColor       = sdl.Color{R: 255, G: 255, B: 255, A: 255}
+	playerDamageColor = sdl.Color{R: 255, G: 64, B: 64, A: 255}
+	criticalColor     = sdl.Color{R: 255, G: 200, B: 0, A: 255}
+	bloodColor        = sdl.Color{R: 160, G: 0, B: 0, A: 255}
+)
+
+// floatingText is a number drifting up from where damage was dealt
+type floatingText struct {
+	text  string
+	size  fontSize
+	color sdl.Color
+	pos   game.Pos
+	start time.Time
+}
+
+// particle is a speck thrown out from a tile, positions are in tiles
+type particle struct {
+	x, y   float64
+	vx, vy float64
+	color  sdl.Color
+	start  time.Time
+}
+
+// addCombatEffects starts the effects for the damage dealt in the last turn
+func (a *App) addCombatEffects(level *game.Level) {
+	now := time.Now()
+
+	for _, event := range level.Events {
+		if event.Type == game.Killed {
+			a.addParticles(event.Pos, bloodColor, now)
+			continue
+		}
+		if event.Type != game.Damaged {
+			continue
+		}
+
+		text := floatingText{
+			text:  strconv.Itoa(event.Amount),
+			size:  smallFont,
+			color: damageColor,
+			pos:   event.Pos,
+			start: now,
+		}
+
+		if event.Target == &level.Player.Character {
+			text.color = playerDamageColor
+			a.damageFlash = now
+		}
+
+		if event.Critical {
+			text.text += "!"
+			text.size = mediumFont
+			text.color = criticalColor
+			a.shake = now
+		}
+
+		a.floatingTexts = append(a.floatingTexts, text)
+	}
+}
+
+// addParticles bursts particles out from the middle of a tile
+func (a *App) addParticles(pos game.Pos, color sdl.Color, now time.Time) {
+	for i := 0; i < deathParticles; i++ {
+		angle := rand.Float64() * 2 * math.Pi
+		speed := particleSpeed * (0.5 + rand.Float64()/2)
+
+		a.particles = append(a.particles, particle{
+			x:     float64(pos.X) + 0.5,
+			y:     float64(pos.Y) + 0.5,
+			vx:    math.Cos(angle) * speed,
+			vy:    math.Sin(angle)*speed - particleSpeed/2,
+			color: color,
+			start: now,
+		})
+	}
+}
+
+// updateShake picks how far the screen is thrown this frame by a critical
+// hit, the map is drawn offset by this much
+func (a *App) updateShake() {
+	a.shakeX, a.shakeY = 0, 0
+
+	t := progress(a.shake, shakeDuration, time.Now())
+	if t >= 1 {
+		return
+	}
+
+	distance := shakeDistance * float64(a.getTileSize()) * (1 - t)
+	a.shakeX = int32((rand.Float64()*2 - 1) * distance)
+	a.shakeY = int32((rand.Float64()*2 - 1) * distance)
+}
+
+// hasEffects reports whether any combat effect is still playing
+func (a *App) hasEffects() bool {
+	now := time.Now()
+	return len(a.floatingTexts) > 0 || len(a.particles) > 0 ||
+		progress(a.damageFlash, damageFlashDuration, now) < 1 ||
+		progress(a.shake, shakeDuration, now) < 1
+}
+
+// drawEffects draws the floating combat text and particles over the map,
+// dropping those that have finished
+func (a *App) drawEffects() {
+	now := time.Now()
+	tileSize := float64(a.getTileSize())
+	originX, originY := a.getMapOrigin()
+
+	particles := a.particles[:0]
+	for _, p := range a.particles {
+		t := progress(p.start, particleDuration, now)
+		if t >= 1 {
+			continue
+		}
+		particles = append(particles, p)
+
+		seconds := now.Sub(p.start).Seconds()
+		x := p.x + p.vx*seconds
+		y := p.y + p.vy*seconds + particleFall*seconds*seconds/2
+		size := int32(math.Max(1, particleSize*tileSize))
+
+		a.renderer.SetDrawColor(p.color.R, p.color.G, p.color.B, uint8(255*(1-t)))
+		a.fillRect(&sdl.Rect{
+			X: originX + int32(x*tileSize) - size/2,
+			Y: originY + int32(y*tileSize) - size/2,
+			W: size,
+			H: size,
+		})
+	}
+	a.particles = particles
+	a.renderer.SetDrawColor(0, 0, 0, 255)
+
+	texts := a.floatingTexts[:0]
+	for _, text := range a.floatingTexts {
+		t := progress(text.start, floatingTextDuration, now)
+		if t >= 1 {
+			continue
+		}
+		texts = append(texts, text)
+
+		w, h := a.getGlyphTextSize(text.text, text.size)
+		rect := a.getTileRect(text.pos.X, text.pos.Y)
+		rise := int32(floatingTextRise * tileSize * t)
+		a.drawGlyphText(text.text, text.size, text.color, uint8(255*(1-t*t)), rect.X+(rect.W-w)/2, rect.Y-h/2-rise)
+	}
+	a.floatingTexts = texts
+}
+
+// drawDamageFlash tints the whole screen red for a moment when the player is
+// hurt
+func (a *App) drawDamageFlash() {
+	t := progress(a.damageFlash, damageFlashDuration, time.Now())
+	if t >= 1 {
+		return
+	}
+
+	a.renderer.SetDrawColor(255, 0, 0, uint8(96*(1-t)))
+	a.fillRect(&sdl.Rect{X: 0, Y: 0, W: a.width, H: a.height})
+	a.renderer.SetDrawColor(0, 0, 0, 255)
+}
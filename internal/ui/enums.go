@@ -5,4 +5,12 @@ type appState int
 const (
 	mainState appState = iota
 	inventoryState
+	editorState
+	targetingState
+	tradeState
+	dialogueState
+	journalState
+	controlsState
+	mapState
+	logState
 )
@@ -6,6 +6,9 @@ import (
 
 func (a *App) toggleInventory() {
 	if a.state == mainState {
+		a.inventoryScroll = 0
+		a.inventoryCursor = 0
+		a.inventoryAction = noAction
 		a.state = inventoryState
 	} else if a.state == inventoryState {
 		a.dragged = nil
@@ -34,8 +37,8 @@ func (a *App) checkForInventoryItem(mx int32, my int32) *game.Item {
 	level := a.loadedLevel
 	items := level.Player.Items
 	for i, item := range items {
-		itemRect := a.getInventoryItemRect(i)
-		if itemRect.HasIntersection(mouseRect) {
+		itemRect, visible := a.getInventoryItemRect(i)
+		if visible && itemRect.HasIntersection(mouseRect) {
 			return item
 		}
 	}
@@ -43,6 +46,20 @@ func (a *App) checkForInventoryItem(mx int32, my int32) *game.Item {
 	return nil
 }
 
+// scrollInventory moves the inventory grid by the given number of rows
+func (a *App) scrollInventory(rows int) {
+	columns := a.getInventoryColumns()
+	totalRows := (len(a.loadedLevel.Player.Items) + columns - 1) / columns
+
+	a.inventoryScroll += rows
+	if a.inventoryScroll > totalRows-inventoryRows {
+		a.inventoryScroll = totalRows - inventoryRows
+	}
+	if a.inventoryScroll < 0 {
+		a.inventoryScroll = 0
+	}
+}
+
 func (a *App) checkForDropItem(mx int32, my int32) bool {
 	mouseRect := a.getMouseRect(mx, my)
 
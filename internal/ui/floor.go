@@ -0,0 +1,329 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// floorChunkSize is how many tiles wide and high each cached piece of the
+// floor is
+const floorChunkSize = 16
+
+// floorChunk is a square of the level's tiles drawn into a texture at sprite
+// size, so the floor is a few copies a frame rather than one per tile
+type floorChunk struct {
+	texture *sdl.Texture
+	stale   bool
+}
+
+// floorTile is how a tile looked when the floor was last updated, to find the
+// tiles a turn changed
+type floorTile struct {
+	symbol  rune
+	overlay rune
+	visible bool
+	seen    bool
+}
+
+// updateFloor marks the chunks holding tiles that changed since the floor was
+// last updated to be redrawn, starting the floor again for a new level
+func (a *App) updateFloor(level *game.Level) {
+	if a.floorLevel != level {
+		a.resetFloor()
+		a.floorLevel = level
+	}
+
+	if a.floorLight != level.Light {
+		a.floorLight = level.Light
+		a.invalidateFloor()
+	}
+
+	for len(a.floorTiles) < len(level.Tiles) {
+		a.floorTiles = append(a.floorTiles, nil)
+	}
+	for y, row := range level.Tiles {
+		for len(a.floorTiles[y]) < len(row) {
+			a.floorTiles[y] = append(a.floorTiles[y], floorTile{})
+		}
+
+		for x, tile := range row {
+			state := floorTile{
+				symbol:  tile.Symbol,
+				overlay: tile.OverlaySymbol,
+				visible: tile.Visible,
+				seen:    tile.Seen,
+			}
+			if a.floorTiles[y][x] != state {
+				a.floorTiles[y][x] = state
+				a.markFloorStale(x, y)
+			}
+		}
+	}
+
+	// debug highlights come and go as the pathfinding does
+	for pos := range a.floorDebug {
+		if !level.Debug[pos] {
+			a.markFloorStale(pos.X, pos.Y)
+		}
+	}
+	for pos := range level.Debug {
+		if !a.floorDebug[pos] {
+			a.markFloorStale(pos.X, pos.Y)
+		}
+	}
+	a.floorDebug = make(map[game.Pos]bool, len(level.Debug))
+	for pos, debug := range level.Debug {
+		a.floorDebug[pos] = debug
+	}
+}
+
+// markFloorStale marks the chunk holding a tile to be redrawn
+func (a *App) markFloorStale(x int, y int) {
+	cx, cy := x/floorChunkSize, y/floorChunkSize
+	if y < 0 || x < 0 || cy >= len(a.floorChunks) || cx >= len(a.floorChunks[cy]) {
+		return
+	}
+
+	if chunk := a.floorChunks[cy][cx]; chunk != nil {
+		chunk.stale = true
+	}
+}
+
+// invalidateFloor marks the cached floor to be redrawn from the level's tiles
+// the next time each piece of it is on screen
+func (a *App) invalidateFloor() {
+	for _, row := range a.floorChunks {
+		for _, chunk := range row {
+			if chunk != nil {
+				chunk.stale = true
+			}
+		}
+	}
+}
+
+// resetFloor throws away the cached floor, for when the level changes or the
+// renderer loses its textures
+func (a *App) resetFloor() {
+	for _, row := range a.floorChunks {
+		for _, chunk := range row {
+			if chunk != nil {
+				chunk.texture.Destroy()
+			}
+		}
+	}
+
+	a.floorChunks = nil
+	a.floorLevel = nil
+	a.floorTiles = nil
+	a.floorDebug = nil
+	a.floorLight = 0
+}
+
+// getTileVariations returns the variation picked for each of a level's tiles,
+// picking them the first time the level is shown so they stay put
+func (a *App) getTileVariations(level *game.Level) [][]int {
+	variations, exists := a.tileVariations[level]
+	if exists {
+		return variations
+	}
+
+	if a.tileVariations == nil {
+		a.tileVariations = make(map[*game.Level][][]int)
+	}
+
+	variations = make([][]int, len(level.Tiles))
+	for y, row := range level.Tiles {
+		variations[y] = make([]int, len(row))
+		for x := range row {
+			variations[y][x] = a.r.Int()
+		}
+	}
+	a.tileVariations[level] = variations
+
+	return variations
+}
+
+// getVisibleTiles returns the range of tiles on screen, from x0, y0 up to but
+// not including x1, y1
+func (a *App) getVisibleTiles() (int, int, int, int) {
+	tileSize := a.getTileSize()
+	originX, originY := a.getMapOrigin()
+
+	x0 := int(-originX / tileSize)
+	y0 := int(-originY / tileSize)
+	x1 := int((a.width-originX)/tileSize) + 1
+	y1 := int((a.height-originY)/tileSize) + 1
+
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if width := int(a.getLevelWidth()); x1 > width {
+		x1 = width
+	}
+	if height := len(a.loadedLevel.Tiles); y1 > height {
+		y1 = height
+	}
+
+	return x0, y0, x1, y1
+}
+
+// drawFloor draws the tiles on screen from the cached chunks, drawing them
+// one by one when the renderer can't draw to textures
+func (a *App) drawFloor() {
+	if a.floorLevel != a.loadedLevel {
+		a.updateFloor(a.loadedLevel)
+	}
+
+	x0, y0, x1, y1 := a.getVisibleTiles()
+	if !a.renderer.RenderTargetSupported() {
+		a.drawTiles(x0, y0, x1, y1, a.getTileRect)
+		a.textureAtlas.SetColorMod(255, 255, 255)
+		return
+	}
+
+	size := floorChunkSize * a.getTileSize()
+	for cy := y0 / floorChunkSize; cy*floorChunkSize < y1; cy++ {
+		for cx := x0 / floorChunkSize; cx*floorChunkSize < x1; cx++ {
+			chunk := a.getFloorChunk(cx, cy)
+			if chunk == nil {
+				continue
+			}
+
+			rect := a.getTileRect(cx*floorChunkSize, cy*floorChunkSize)
+			rect.W, rect.H = size, size
+			a.copy(chunk.texture, nil, rect)
+		}
+	}
+
+	// keep only the chunks around the screen so big levels don't fill
+	// video memory as they are explored
+	a.dropFloorChunks(x0/floorChunkSize-1, y0/floorChunkSize-1, x1/floorChunkSize+1, y1/floorChunkSize+1)
+}
+
+// dropFloorChunks destroys the chunks outside cx0, cy0 to cx1, cy1
+func (a *App) dropFloorChunks(cx0 int, cy0 int, cx1 int, cy1 int) {
+	for cy, row := range a.floorChunks {
+		for cx, chunk := range row {
+			if chunk == nil || (cx >= cx0 && cx <= cx1 && cy >= cy0 && cy <= cy1) {
+				continue
+			}
+
+			chunk.texture.Destroy()
+			row[cx] = nil
+		}
+	}
+}
+
+// getFloorChunk returns a chunk of the floor, drawing it first if it hasn't
+// been or its tiles have changed since
+func (a *App) getFloorChunk(cx int, cy int) *floorChunk {
+	for len(a.floorChunks) <= cy {
+		a.floorChunks = append(a.floorChunks, nil)
+	}
+	for len(a.floorChunks[cy]) <= cx {
+		a.floorChunks[cy] = append(a.floorChunks[cy], nil)
+	}
+
+	chunk := a.floorChunks[cy][cx]
+	if chunk == nil {
+		size := int32(floorChunkSize * spriteHeight)
+		tex, err := a.renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_TARGET, size, size)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create floor texture: %s\n", err)
+			return nil
+		}
+		tex.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+		chunk = &floorChunk{texture: tex, stale: true}
+		a.floorChunks[cy][cx] = chunk
+	}
+
+	if chunk.stale {
+		a.renderFloorChunk(chunk, cx, cy)
+	}
+
+	return chunk
+}
+
+// renderFloorChunk draws a chunk's tiles into its texture
+func (a *App) renderFloorChunk(chunk *floorChunk, cx int, cy int) {
+	err := a.renderer.SetRenderTarget(chunk.texture)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to draw floor texture: %s\n", err)
+		return
+	}
+
+	a.renderer.SetDrawColor(0, 0, 0, 0)
+	a.renderer.Clear()
+	a.renderer.SetDrawColor(0, 0, 0, 255)
+
+	x0, y0 := cx*floorChunkSize, cy*floorChunkSize
+	a.drawTiles(x0, y0, x0+floorChunkSize, y0+floorChunkSize, func(x int, y int) *sdl.Rect {
+		return &sdl.Rect{
+			X: int32(x-x0) * spriteHeight,
+			Y: int32(y-y0) * spriteHeight,
+			W: spriteHeight,
+			H: spriteHeight,
+		}
+	})
+	a.textureAtlas.SetColorMod(255, 255, 255)
+
+	a.renderer.SetRenderTarget(nil)
+	chunk.stale = false
+}
+
+// drawTiles draws the tiles from x0, y0 up to x1, y1 at the rects getRect
+// gives for them, shaded by the level's light and what the player has seen
+func (a *App) drawTiles(x0 int, y0 int, x1 int, y1 int, getRect func(x int, y int) *sdl.Rect) {
+	level := a.loadedLevel
+	light := uint8(level.Light)
+	variations := a.getTileVariations(level)
+
+	for y := y0; y < y1 && y < len(level.Tiles); y++ {
+		row := level.Tiles[y]
+		for x := x0; x < x1 && x < len(row); x++ {
+			tile := row[x]
+			if tile.Symbol == game.EmptyTile {
+				continue
+			}
+
+			if !tile.Visible && !tile.Seen && a.state != editorState {
+				continue
+			}
+
+			// secret doors look like the surrounding stone until found
+			symbol := tile.Symbol
+			if tile.OverlaySymbol == game.SecretDoorTile && a.state != editorState {
+				symbol = game.StoneTile
+			}
+
+			srcRects := a.textureIndex[symbol]
+			srcRect := srcRects[variations[y][x]%len(srcRects)]
+			destRect := getRect(x, y)
+
+			if level.Debug[game.Pos{X: x, Y: y}] {
+				a.textureAtlas.SetColorMod(128, 0, 0)
+			} else if tile.Seen && !tile.Visible && a.state != editorState {
+				a.textureAtlas.SetColorMod(light/2, light/2, light/2)
+			} else {
+				a.textureAtlas.SetColorMod(light, light, light)
+			}
+
+			a.copy(a.textureAtlas, &srcRect, destRect)
+
+			if overlayRects, exists := a.textureIndex[tile.OverlaySymbol]; exists {
+				if tile.OverlaySymbol != game.SecretDoorTile {
+					a.copy(a.textureAtlas, &overlayRects[0], destRect)
+				} else if a.state == editorState {
+					a.copy(a.textureAtlas, &a.textureIndex[game.ClosedDoorTile][0], destRect)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// the size of the level and screen the floor benchmarks draw
+const (
+	benchmarkLevelSize    = 500
+	benchmarkScreenWidth  = 1280
+	benchmarkScreenHeight = 720
+)
+
+// newBenchmarkApp returns an app drawing a big explored level of dirt with a
+// software renderer, the camera in the middle of the level
+func newBenchmarkApp(b *testing.B) *App {
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, benchmarkScreenWidth, benchmarkScreenHeight, 32, sdl.PIXELFORMAT_ARGB8888)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(surface.Free)
+
+	renderer, err := sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { renderer.Destroy() })
+
+	atlas, err := renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STATIC, spriteHeight, spriteHeight)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	level := &game.Level{
+		Tiles: make([][]game.Tile, benchmarkLevelSize),
+		Debug: make(map[game.Pos]bool),
+		Light: 255,
+	}
+	for y := range level.Tiles {
+		level.Tiles[y] = make([]game.Tile, benchmarkLevelSize)
+		for x := range level.Tiles[y] {
+			level.Tiles[y][x] = game.Tile{Symbol: game.DirtTile, Visible: true, Seen: true}
+		}
+	}
+
+	a := &App{
+		width:        benchmarkScreenWidth,
+		height:       benchmarkScreenHeight,
+		cameraX:      benchmarkLevelSize / 2,
+		cameraY:      benchmarkLevelSize / 2,
+		zoom:         defaultZoom,
+		state:        mainState,
+		r:            rand.New(rand.NewSource(1)),
+		loadedLevel:  level,
+		renderer:     renderer,
+		textureAtlas: atlas,
+		textureIndex: map[rune][]sdl.Rect{
+			game.DirtTile: {{W: spriteHeight, H: spriteHeight}},
+		},
+	}
+	a.updateFloor(level)
+
+	return a
+}
+
+func BenchmarkDrawEveryTile(b *testing.B) {
+	a := newBenchmarkApp(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		a.drawTiles(0, 0, benchmarkLevelSize, benchmarkLevelSize, a.getTileRect)
+	}
+}
+
+func BenchmarkDrawVisibleTiles(b *testing.B) {
+	a := newBenchmarkApp(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		x0, y0, x1, y1 := a.getVisibleTiles()
+		a.drawTiles(x0, y0, x1, y1, a.getTileRect)
+	}
+}
+
+func BenchmarkDrawFloorChunks(b *testing.B) {
+	a := newBenchmarkApp(b)
+	a.drawFloor()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		a.drawFloor()
+	}
+}
+
+// BenchmarkDrawFloorChunksAfterTurn changes a tile on screen every frame, as
+// a door opening or the player's sight moving does, so only its chunk is
+// redrawn
+func BenchmarkDrawFloorChunksAfterTurn(b *testing.B) {
+	a := newBenchmarkApp(b)
+	a.drawFloor()
+	b.ResetTimer()
+
+	tile := &a.loadedLevel.Tiles[benchmarkLevelSize/2][benchmarkLevelSize/2]
+	for i := 0; i < b.N; i++ {
+		tile.Visible = !tile.Visible
+		a.updateFloor(a.loadedLevel)
+		a.drawFloor()
+	}
+}
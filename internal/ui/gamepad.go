@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// padDeadzone is how far the left stick has to be pushed to count as a move
+const padDeadzone = 16000
+
+// padKeys maps gamepad buttons to the keys they stand in for on screens other
+// than the main game screen
+var padKeys = map[uint8]sdl.Scancode{
+	sdl.CONTROLLER_BUTTON_DPAD_UP:       sdl.SCANCODE_UP,
+	sdl.CONTROLLER_BUTTON_DPAD_DOWN:     sdl.SCANCODE_DOWN,
+	sdl.CONTROLLER_BUTTON_DPAD_LEFT:     sdl.SCANCODE_LEFT,
+	sdl.CONTROLLER_BUTTON_DPAD_RIGHT:    sdl.SCANCODE_RIGHT,
+	sdl.CONTROLLER_BUTTON_A:             sdl.SCANCODE_RETURN,
+	sdl.CONTROLLER_BUTTON_B:             sdl.SCANCODE_ESCAPE,
+	sdl.CONTROLLER_BUTTON_X:             sdl.SCANCODE_U,
+	sdl.CONTROLLER_BUTTON_Y:             sdl.SCANCODE_I,
+	sdl.CONTROLLER_BUTTON_LEFTSHOULDER:  sdl.SCANCODE_D,
+	sdl.CONTROLLER_BUTTON_RIGHTSHOULDER: sdl.SCANCODE_E,
+	sdl.CONTROLLER_BUTTON_BACK:          sdl.SCANCODE_TAB,
+	sdl.CONTROLLER_BUTTON_START:         sdl.SCANCODE_ESCAPE,
+}
+
+// addController opens a newly connected gamepad
+func (a *App) addController(index int) {
+	if !sdl.IsGameController(index) {
+		return
+	}
+
+	controller := sdl.GameControllerOpen(index)
+	if controller == nil {
+		return
+	}
+
+	a.controllers[controller.Joystick().InstanceID()] = controller
+}
+
+// removeController closes a gamepad that has been unplugged
+func (a *App) removeController(id sdl.JoystickID) {
+	if controller, exists := a.controllers[id]; exists {
+		controller.Close()
+		delete(a.controllers, id)
+	}
+}
+
+// handleButton acts on a gamepad button press, using the action bindings on
+// the main screen and standing in for keys everywhere else
+func (a *App) handleButton(button uint8) {
+	if a.state == mainState {
+		if action, exists := a.padBindings[button]; exists {
+			a.doAction(action)
+		}
+		return
+	}
+
+	scancode, exists := padKeys[button]
+	if !exists {
+		return
+	}
+
+	a.handleKey(&sdl.KeyboardEvent{
+		Type:   sdl.KEYDOWN,
+		Keysym: sdl.Keysym{Scancode: scancode},
+	})
+}
+
+// handleAxis treats pushing the left stick like pressing the d-pad, moving
+// once each time the stick leaves the deadzone
+func (a *App) handleAxis(e *sdl.ControllerAxisEvent) {
+	direction := 0
+	if e.Value > padDeadzone {
+		direction = 1
+	} else if e.Value < -padDeadzone {
+		direction = -1
+	}
+
+	switch e.Axis {
+	case sdl.CONTROLLER_AXIS_LEFTX:
+		if direction != a.stickX && direction == 1 {
+			a.handleButton(sdl.CONTROLLER_BUTTON_DPAD_RIGHT)
+		} else if direction != a.stickX && direction == -1 {
+			a.handleButton(sdl.CONTROLLER_BUTTON_DPAD_LEFT)
+		}
+		a.stickX = direction
+	case sdl.CONTROLLER_AXIS_LEFTY:
+		if direction != a.stickY && direction == 1 {
+			a.handleButton(sdl.CONTROLLER_BUTTON_DPAD_DOWN)
+		} else if direction != a.stickY && direction == -1 {
+			a.handleButton(sdl.CONTROLLER_BUTTON_DPAD_UP)
+		}
+		a.stickY = direction
+	}
+}
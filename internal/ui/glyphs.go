@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// the printable ASCII characters put in each glyph atlas
+const (
+	firstGlyph = ' '
+	lastGlyph  = '~'
+)
+
+// glyphAtlasWidth is how wide a glyph atlas is before wrapping to a new row
+const glyphAtlasWidth = 1024
+
+// glyphAtlas is every printable ASCII character of a font size drawn once, in
+// white, into a single texture. Text that changes every few frames, like
+// damage numbers and counters, is drawn from it a character at a time rather
+// than rendering and caching each new string.
+type glyphAtlas struct {
+	texture *sdl.Texture
+	glyphs  map[rune]sdl.Rect
+	height  int32
+}
+
+// getGlyphAtlas returns the glyph atlas for a font size, building it the first
+// time it is needed
+func (a *App) getGlyphAtlas(size fontSize) *glyphAtlas {
+	if atlas, exists := a.glyphAtlases[size]; exists {
+		return atlas
+	}
+
+	atlas := a.buildGlyphAtlas(size)
+	a.glyphAtlases[size] = atlas
+	return atlas
+}
+
+func (a *App) buildGlyphAtlas(size fontSize) *glyphAtlas {
+	font := a.getFont(size)
+	height := int32(font.Height())
+
+	// render each glyph and lay them out in rows
+	surfaces := make(map[rune]*sdl.Surface)
+	glyphs := make(map[rune]sdl.Rect)
+	var x, y int32
+	for r := rune(firstGlyph); r <= lastGlyph; r++ {
+		surface, err := font.RenderUTF8Blended(string(r), textColor)
+		if err != nil {
+			continue
+		}
+
+		if x+surface.W > glyphAtlasWidth {
+			x = 0
+			y += height
+		}
+
+		surfaces[r] = surface
+		glyphs[r] = sdl.Rect{X: x, Y: y, W: surface.W, H: surface.H}
+		x += surface.W
+	}
+
+	atlasSurface, err := sdl.CreateRGBSurfaceWithFormat(0, glyphAtlasWidth, y+height, 32, sdl.PIXELFORMAT_ARGB8888)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create glyph atlas: %s\n", err)
+		panic(err)
+	}
+	defer atlasSurface.Free()
+
+	// copy the glyphs' alpha as it is rather than blending onto the
+	// empty atlas
+	for r, surface := range surfaces {
+		rect := glyphs[r]
+		surface.SetBlendMode(sdl.BLENDMODE_NONE)
+		surface.Blit(nil, atlasSurface, &rect)
+		surface.Free()
+	}
+
+	tex, err := a.renderer.CreateTextureFromSurface(atlasSurface)
+	if err != nil {
+		panic(err)
+	}
+	tex.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	return &glyphAtlas{
+		texture: tex,
+		glyphs:  glyphs,
+		height:  height,
+	}
+}
+
+// getGlyphTextSize returns how big a string is when drawn from the glyph atlas
+func (a *App) getGlyphTextSize(s string, size fontSize) (int32, int32) {
+	atlas := a.getGlyphAtlas(size)
+
+	var w int32
+	for _, r := range s {
+		w += atlas.glyphs[r].W
+	}
+
+	return w, atlas.height
+}
+
+// drawGlyphText draws a string a character at a time from the glyph atlas,
+// tinted to a color and faded to an alpha. Characters missing from the atlas
+// are skipped. It returns the size of the drawn text.
+func (a *App) drawGlyphText(s string, size fontSize, color sdl.Color, alpha uint8, x int32, y int32) (int32, int32) {
+	atlas := a.getGlyphAtlas(size)
+	atlas.texture.SetColorMod(color.R, color.G, color.B)
+	atlas.texture.SetAlphaMod(alpha)
+
+	start := x
+	for _, r := range s {
+		src, exists := atlas.glyphs[r]
+		if !exists {
+			continue
+		}
+
+		a.copy(atlas.texture, &src, &sdl.Rect{X: x, Y: y, W: src.W, H: src.H})
+		x += src.W
+	}
+
+	atlas.texture.SetColorMod(255, 255, 255)
+	atlas.texture.SetAlphaMod(255)
+
+	return x - start, atlas.height
+}
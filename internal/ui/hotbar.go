@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// maxHotbarSlots is the number of abilities bound to the number keys
+const maxHotbarSlots = 9
+
+// useAbility returns the input casting the ability in the given hotbar slot,
+// or enters targeting mode and returns nil if it needs a target
+func (a *App) useAbility(slot int) *game.Input {
+	abilities := a.loadedLevel.Player.Abilities
+	if slot >= len(abilities) {
+		return nil
+	}
+
+	if abilities[slot].Range > 0 {
+		a.startCasting(slot)
+		return nil
+	}
+
+	return a.castInput(slot, a.loadedLevel.Player.Pos)
+}
+
+func (a *App) drawHotbar() {
+	player := a.loadedLevel.Player
+
+	for i, ability := range player.Abilities {
+		if i >= maxHotbarSlots {
+			break
+		}
+
+		slotRect := a.getHotbarSlotRect(i)
+		a.copy(a.slotBackground, nil, slotRect)
+
+		color := sdl.Color{R: 255, G: 255, B: 255}
+		if player.Mana < ability.ManaCost {
+			color = sdl.Color{R: 64, G: 64, B: 255}
+		}
+
+		label := strconv.Itoa(i+1) + " " + ability.Name
+		tex := a.stringToTexture(label, smallFont, color)
+		_, _, w, h, err := tex.Query()
+		if err == nil {
+			a.copy(tex, nil, &sdl.Rect{X: slotRect.X + 4, Y: slotRect.Y, W: w, H: h})
+		}
+
+		cost := strconv.Itoa(ability.ManaCost) + " MP"
+		if ability.Remaining > 0 {
+			cost += " (" + strconv.Itoa(ability.Remaining) + ")"
+		}
+		_, h = a.getGlyphTextSize(cost, smallFont)
+		a.drawGlyphText(cost, smallFont, color, 255, slotRect.X+4, slotRect.Y+slotRect.H-h)
+
+		// grey out abilities that are cooling down
+		if ability.Remaining > 0 {
+			a.copy(a.eventBackground, nil, slotRect)
+		}
+	}
+
+	// draw player stats above the hotbar
+	stats := "HP " + strconv.Itoa(player.Hitpoints) + "/" + strconv.Itoa(player.MaxHitpoints) +
+		"  MP " + strconv.Itoa(player.Mana) + "/" + strconv.Itoa(player.MaxMana) +
+		"  Gold " + strconv.Itoa(player.Gold)
+	_, h := a.getGlyphTextSize(stats, smallFont)
+	slotRect := a.getHotbarSlotRect(0)
+	a.drawGlyphText(stats, smallFont, textColor, 255, slotRect.X, slotRect.Y-h)
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// maxQueuedInputs is how many inputs can wait for the game to finish a turn,
+// any more are dropped so holding a key can't build up a backlog of turns
+const maxQueuedInputs = 3
+
+// queueInput queues an input for the game, to be sent once the game has
+// handed back the level from the last turn and it has been drawn. None inputs
+// are dropped.
+func (a *App) queueInput(input *game.Input) {
+	if input.Type == game.None || len(a.inputQueue) >= maxQueuedInputs {
+		return
+	}
+
+	a.inputQueue = append(a.inputQueue, input)
+}
+
+// sendQueuedInput hands the level to the game to take a turn with the next
+// queued input. The game owns the level until it sends it back, so only one
+// turn is ever running and the send never blocks.
+func (a *App) sendQueuedInput() {
+	if a.turnPending || len(a.inputQueue) == 0 {
+		return
+	}
+
+	input := a.inputQueue[0]
+	a.inputQueue = a.inputQueue[1:]
+	a.turnPending = true
+	a.startTurn()
+	a.game.InputCh <- input
+}
+
+// pollTurn takes the level back if the game has finished its turn, without
+// waiting for it
+func (a *App) pollTurn() {
+	if !a.turnPending {
+		return
+	}
+
+	select {
+	case level := <-a.game.LevelCh:
+		a.finishTurn(level)
+	default:
+	}
+}
+
+// awaitTurn waits for the game to finish every queued turn, for anything that
+// needs to look at the level
+func (a *App) awaitTurn() {
+	a.sendQueuedInput()
+	for a.turnPending {
+		a.finishTurn(<-a.game.LevelCh)
+		a.sendQueuedInput()
+	}
+}
+
+// finishTurn takes back the level after a turn. The next queued input waits
+// until the level has been drawn, so every turn is seen even when moves are
+// queued.
+func (a *App) finishTurn(level *game.Level) {
+	a.turnPending = false
+	a.endTurn()
+	a.dirty = true
+	state := a.state
+	a.updateLevel(level)
+
+	// the turn opened a conversation or trade, the queued moves were meant
+	// for the map
+	if a.state != state {
+		a.inputQueue = a.inputQueue[:0]
+	}
+}
+
+// quit tells the game to stop. The game never waits on the ui to take a
+// level back, so it can't be left blocked whether or not a turn is running.
+func (a *App) quit() {
+	select {
+	case a.game.InputCh <- &game.Input{Type: game.QuitGame}:
+	default:
+	}
+}
+
+// readsLevel reports whether handling an event looks at the level, so it has
+// to wait for the game to hand the level back. Everything else, like moving
+// on the main screen, is handled while the game is taking a turn.
+func (a *App) readsLevel(event sdl.Event) bool {
+	switch e := event.(type) {
+	case *sdl.KeyboardEvent:
+		if a.state != mainState {
+			return true
+		}
+
+		// key releases do nothing on the main screen
+		return e.Type == sdl.KEYDOWN && !queuesTurn(a.boundAction(e))
+	case *sdl.ControllerButtonEvent:
+		return e.Type == sdl.CONTROLLERBUTTONDOWN && a.buttonReadsLevel(e.Button)
+	case *sdl.ControllerAxisEvent:
+		switch e.Axis {
+		case sdl.CONTROLLER_AXIS_LEFTX:
+			return a.buttonReadsLevel(sdl.CONTROLLER_BUTTON_DPAD_LEFT) || a.buttonReadsLevel(sdl.CONTROLLER_BUTTON_DPAD_RIGHT)
+		case sdl.CONTROLLER_AXIS_LEFTY:
+			return a.buttonReadsLevel(sdl.CONTROLLER_BUTTON_DPAD_UP) || a.buttonReadsLevel(sdl.CONTROLLER_BUTTON_DPAD_DOWN)
+		default:
+			return false
+		}
+	case *sdl.MouseMotionEvent:
+		return a.state == editorState
+	case *sdl.MouseWheelEvent:
+		return a.state == inventoryState || a.state == logState
+	case *sdl.WindowEvent:
+		return e.Event == sdl.WINDOWEVENT_SIZE_CHANGED
+	case *sdl.RenderEvent, *sdl.ControllerDeviceEvent:
+		return false
+	default:
+		return true
+	}
+}
+
+// buttonReadsLevel reports whether pressing a gamepad button looks at the
+// level, buttons only queue a turn when bound to one on the main screen
+func (a *App) buttonReadsLevel(button uint8) bool {
+	if a.state != mainState {
+		return true
+	}
+
+	action, exists := a.padBindings[button]
+	return exists && !queuesTurn(action)
+}
+
+// queuesTurn reports whether an action on the main screen only queues a turn
+func queuesTurn(action keyAction) bool {
+	switch action {
+	case actionUp, actionDown, actionLeft, actionRight,
+		actionTakeAll, actionCloseDoor, actionSearch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// inspect opens the inspection panel for the item under the mouse, or closes
+// it if there is none
+func (a *App) inspect(mx int32, my int32) {
+	a.inspected = a.hoveredItem(mx, my)
+}
+
+// checkForEquippedItem returns the equipped item whose slot is under the mouse
+func (a *App) checkForEquippedItem(mx int32, my int32) *game.Item {
+	mouseRect := a.getMouseRect(mx, my)
+	player := a.loadedLevel.Player
+
+	if player.Weapon != nil && a.getWeaponSlotRect().HasIntersection(mouseRect) {
+		return player.Weapon
+	}
+	if player.Armor != nil && a.getArmorSlotRect().HasIntersection(mouseRect) {
+		return player.Armor
+	}
+
+	return nil
+}
+
+// isInspectable reports whether the inspected item is still somewhere the
+// player can see it
+func (a *App) isInspectable(item *game.Item) bool {
+	level := a.loadedLevel
+	player := level.Player
+
+	if item == player.Weapon || item == player.Armor {
+		return true
+	}
+
+	for _, items := range [][]*game.Item{player.Items, level.Items[player.Pos]} {
+		for _, i := range items {
+			if i == item {
+				return true
+			}
+		}
+	}
+
+	if level.Trading != nil {
+		for _, i := range level.Trading.Items {
+			if i == item {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *App) drawInspection() {
+	item := a.inspected
+	if item == nil || a.state == editorState {
+		return
+	}
+
+	if !a.isInspectable(item) {
+		a.inspected = nil
+		return
+	}
+
+	panelRect := a.getInspectionRect()
+	a.copy(a.inventoryBackground, nil, panelRect)
+
+	// item sprite and name
+	itemSize := a.getSlotSize()
+	spriteRect := &sdl.Rect{X: panelRect.X + 8, Y: panelRect.Y + 8, W: itemSize, H: itemSize}
+	a.copy(a.slotBackground, nil, spriteRect)
+	if srcRects, exists := a.textureIndex[item.Symbol]; exists {
+		a.copy(a.textureAtlas, &srcRects[0], spriteRect)
+	}
+
+	tex := a.stringToTexture(itemName(item), mediumFont, textColor)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: spriteRect.X + spriteRect.W + 8, Y: spriteRect.Y + (spriteRect.H-h)/2, W: w, H: h})
+	}
+
+	// details
+	lines := []detailLine{{"Type: " + itemTypeName(item.Type), textColor}}
+	lines = append(lines, itemStats(item)...)
+	if item.Count > 1 {
+		lines = append(lines, detailLine{"Count: " + strconv.Itoa(item.Count), textColor})
+	}
+	lines = append(lines, detailLine{"Weight: " + strconv.FormatFloat(item.Weight*float64(item.Count), 'f', 1, 64), textColor})
+	if item.Value > 0 {
+		lines = append(lines, detailLine{"Value: " + strconv.Itoa(item.Price()) + " gold", textColor})
+	}
+	if comparison := itemComparison(item, a.loadedLevel.Player); len(comparison) > 0 {
+		lines = append(lines, detailLine{"", textColor})
+		lines = append(lines, comparison...)
+	}
+
+	y := spriteRect.Y + spriteRect.H + 8
+	for _, line := range lines {
+		if line.text == "" {
+			y += a.getItemSize() / 2
+			continue
+		}
+
+		tex := a.stringToTexture(line.text, smallFont, line.color)
+		_, _, w, h, err := tex.Query()
+		if err == nil {
+			a.copy(tex, nil, &sdl.Rect{X: panelRect.X + 8, Y: y, W: w, H: h})
+			y += h
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// inventoryAction is an action waiting for the player to pick an item
+type inventoryAction int
+
+const (
+	noAction inventoryAction = iota
+	equipAction
+	dropAction
+	useAction
+	inspectAction
+)
+
+var actionPrompts = map[inventoryAction]string{
+	equipAction:   "Equip what?",
+	dropAction:    "Drop what?",
+	useAction:     "Use what?",
+	inspectAction: "Inspect what?",
+}
+
+// slotLetters label the inventory slots in order, leaving out the letters of
+// the action keys so every labelled slot can be picked
+const slotLetters = "abcfghjklmnopqrstvwyz"
+
+// slotLetter returns the letter labelling an inventory slot, or 0 if the slot
+// has none
+func slotLetter(i int) rune {
+	if i < 0 || i >= len(slotLetters) {
+		return 0
+	}
+
+	return rune(slotLetters[i])
+}
+
+// moveInventoryCursor moves the cursor around the inventory grid, scrolling
+// to keep it on screen
+func (a *App) moveInventoryCursor(dx int, dy int) {
+	a.setInventoryCursor(a.inventoryCursor + dx + dy*a.getInventoryColumns())
+}
+
+func (a *App) setInventoryCursor(i int) {
+	items := a.loadedLevel.Player.Items
+	if i >= len(items) {
+		i = len(items) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	a.inventoryCursor = i
+
+	row := i / a.getInventoryColumns()
+	if row < a.inventoryScroll {
+		a.inventoryScroll = row
+	} else if row >= a.inventoryScroll+inventoryRows {
+		a.inventoryScroll = row - inventoryRows + 1
+	}
+}
+
+// clampInventoryCursor keeps the cursor on an item as items are dropped or
+// used up, only scrolling to it when it had to move
+func (a *App) clampInventoryCursor() {
+	if a.inventoryCursor < 0 || a.inventoryCursor >= len(a.loadedLevel.Player.Items) {
+		a.setInventoryCursor(a.inventoryCursor)
+	}
+}
+
+// cursorItem returns the item under the inventory cursor
+func (a *App) cursorItem() *game.Item {
+	items := a.loadedLevel.Player.Items
+	if a.inventoryCursor < 0 || a.inventoryCursor >= len(items) {
+		return nil
+	}
+
+	return items[a.inventoryCursor]
+}
+
+// startInventoryAction waits for the player to pick the item for an action
+func (a *App) startInventoryAction(action inventoryAction) {
+	a.inventoryAction = action
+}
+
+// selectInventorySlot picks an item by its slot letter, finishing the pending
+// action or moving the cursor to it
+func (a *App) selectInventorySlot(letter rune) *game.Input {
+	i := strings.IndexRune(slotLetters, letter)
+	if i < 0 || i >= len(a.loadedLevel.Player.Items) {
+		return nil
+	}
+
+	a.setInventoryCursor(i)
+	if a.inventoryAction == noAction {
+		return nil
+	}
+
+	return a.finishInventoryAction()
+}
+
+// finishInventoryAction applies the pending action, or inspection if there is
+// none, to the item under the cursor
+func (a *App) finishInventoryAction() *game.Input {
+	action := a.inventoryAction
+	a.inventoryAction = noAction
+
+	item := a.cursorItem()
+	if item == nil {
+		return nil
+	}
+
+	input := &game.Input{Item: item}
+	switch action {
+	case equipAction:
+		if item.Type != game.Weapon && item.Type != game.Armor {
+			a.addMessage("You can't equip the " + item.Name)
+			return nil
+		}
+		input.Type = game.EquipItem
+	case dropAction:
+		input.Type = game.DropItem
+	case useAction:
+		input.Type = game.UseItem
+	default:
+		if a.inspected == item {
+			a.inspected = nil
+		} else {
+			a.inspected = item
+		}
+		return nil
+	}
+
+	return input
+}
+
+// handleInventoryKey handles a key press in the inventory screen, returning
+// the input to send to the game, if any
+func (a *App) handleInventoryKey(scancode sdl.Scancode) *game.Input {
+	switch scancode {
+	case sdl.SCANCODE_ESCAPE:
+		if a.inventoryAction != noAction {
+			a.inventoryAction = noAction
+		} else {
+			a.toggleInventory()
+		}
+	case sdl.SCANCODE_UP:
+		a.moveInventoryCursor(0, -1)
+	case sdl.SCANCODE_DOWN:
+		a.moveInventoryCursor(0, 1)
+	case sdl.SCANCODE_LEFT:
+		a.moveInventoryCursor(-1, 0)
+	case sdl.SCANCODE_RIGHT:
+		a.moveInventoryCursor(1, 0)
+	case sdl.SCANCODE_RETURN:
+		return a.finishInventoryAction()
+	default:
+		if scancode < sdl.SCANCODE_A || scancode > sdl.SCANCODE_Z {
+			return nil
+		}
+		letter := rune('a' + scancode - sdl.SCANCODE_A)
+
+		// the action keys aren't slot letters, so they always start an action
+		switch scancode {
+		case sdl.SCANCODE_I:
+			a.toggleInventory()
+		case sdl.SCANCODE_E:
+			a.startInventoryAction(equipAction)
+		case sdl.SCANCODE_D:
+			a.startInventoryAction(dropAction)
+		case sdl.SCANCODE_U:
+			a.startInventoryAction(useAction)
+		case sdl.SCANCODE_X:
+			a.startInventoryAction(inspectAction)
+		default:
+			return a.selectInventorySlot(letter)
+		}
+	}
+
+	return nil
+}
+
+// drawInventoryKeys draws slot letters, the cursor and any pending prompt
+func (a *App) drawInventoryKeys() {
+	player := a.loadedLevel.Player
+
+	for i := range player.Items {
+		slotRect, visible := a.getInventoryItemRect(i)
+		if !visible {
+			continue
+		}
+
+		if letter := slotLetter(i); letter != 0 {
+			tex := a.stringToTexture(string(letter), smallFont, textColor)
+			_, _, w, h, err := tex.Query()
+			if err == nil {
+				a.copy(tex, nil, &sdl.Rect{X: slotRect.X + 2, Y: slotRect.Y, W: w, H: h})
+			}
+		}
+
+		if i == a.inventoryCursor {
+			a.renderer.SetDrawColor(255, 255, 255, 255)
+			a.drawRect(slotRect)
+			a.renderer.SetDrawColor(0, 0, 0, 255)
+		}
+	}
+
+	prompt := "E equip  D drop  U use  X inspect  Enter inspect selected"
+	if a.inventoryAction != noAction {
+		prompt = actionPrompts[a.inventoryAction] + " (letter, Enter for selected, Esc to cancel)"
+	}
+
+	inventoryRect := a.getInventoryBackdropRect()
+	tex := a.stringToTexture(prompt, smallFont, textColor)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		gridRect, _ := a.getInventoryItemRect(a.inventoryScroll * a.getInventoryColumns())
+		a.copy(tex, nil, &sdl.Rect{X: inventoryRect.X + 8, Y: gridRect.Y - h - 4, W: w, H: h})
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func (a *App) toggleJournal() {
+	if a.state == mainState {
+		a.state = journalState
+	} else if a.state == journalState {
+		a.state = mainState
+	}
+}
+
+func (a *App) drawJournal() {
+	journal := a.loadedLevel.Journal
+	if journal == nil {
+		return
+	}
+
+	a.copy(a.inventoryBackground, nil, a.getInventoryBackdropRect())
+
+	white := sdl.Color{R: 255, G: 255, B: 255}
+	a.drawJournalText("Quests", 0, white)
+	if len(journal.Quests) == 0 {
+		a.drawJournalText("You have no quests", 1, white)
+		return
+	}
+
+	for i, quest := range journal.Quests {
+		color := white
+		if quest.State == game.QuestFinished {
+			color = sdl.Color{R: 128, G: 128, B: 128}
+		}
+		a.drawJournalText(quest.Title+" - "+quest.Describe(), i+1, color)
+	}
+}
+
+func (a *App) drawJournalText(s string, row int, color sdl.Color) {
+	rowRect := a.getTradeRowRect(merchantSide, row-1)
+
+	tex := a.stringToTexture(s, smallFont, color)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: rowRect.X, Y: rowRect.Y, W: w, H: h})
+	}
+}
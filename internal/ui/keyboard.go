@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// handleKey handles a key press or release for the current screen
+func (a *App) handleKey(e *sdl.KeyboardEvent) {
+	// fullscreen and the stats overlay can be toggled from any screen
+	if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+		switch a.bindings[e.Keysym.Scancode] {
+		case actionFullscreen:
+			a.toggleFullscreen()
+			return
+		case actionStats:
+			a.toggleStats()
+			return
+		}
+	}
+
+	switch a.state {
+	case mainState:
+		// act on key presses, repeating moves while keys are held
+		if e.Type == sdl.KEYDOWN {
+			action := a.boundAction(e)
+			if e.Repeat != 0 && action == actionNone {
+				break
+			}
+
+			a.doAction(action)
+		}
+
+	case inventoryState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			input := a.handleInventoryKey(e.Keysym.Scancode)
+			if input != nil {
+				a.queueInput(input)
+			}
+		}
+
+	case tradeState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			switch e.Keysym.Scancode {
+			case sdl.SCANCODE_ESCAPE, sdl.SCANCODE_I:
+				input := game.Input{
+					Type: game.EndTrade,
+				}
+
+				a.queueInput(&input)
+			default:
+				// do nothing
+			}
+		}
+
+	case dialogueState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			input := game.Input{
+				Type:   game.Choose,
+				Choice: -1,
+			}
+
+			switch e.Keysym.Scancode {
+			case sdl.SCANCODE_1, sdl.SCANCODE_2, sdl.SCANCODE_3,
+				sdl.SCANCODE_4, sdl.SCANCODE_5, sdl.SCANCODE_6,
+				sdl.SCANCODE_7, sdl.SCANCODE_8, sdl.SCANCODE_9:
+				input.Choice = int(e.Keysym.Scancode - sdl.SCANCODE_1)
+				if input.Choice < len(a.loadedLevel.Choices()) {
+					a.choiceCursor = 0
+					a.queueInput(&input)
+				}
+			case sdl.SCANCODE_UP:
+				a.moveChoiceCursor(-1)
+			case sdl.SCANCODE_DOWN:
+				a.moveChoiceCursor(1)
+			case sdl.SCANCODE_RETURN:
+				input.Choice = a.choiceCursor
+				a.choiceCursor = 0
+				a.queueInput(&input)
+			case sdl.SCANCODE_ESCAPE:
+				a.choiceCursor = 0
+				a.queueInput(&input)
+			default:
+				// do nothing
+			}
+		}
+
+	case journalState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			if e.Keysym.Scancode == sdl.SCANCODE_ESCAPE || a.bindings[e.Keysym.Scancode] == actionJournal {
+				a.toggleJournal()
+			}
+		}
+
+	case logState:
+		if e.Type == sdl.KEYDOWN {
+			a.handleLogKey(e)
+		}
+
+	case mapState:
+		if e.Type == sdl.KEYDOWN {
+			switch a.boundAction(e) {
+			case actionUp:
+				a.panMap(0, -1)
+			case actionDown:
+				a.panMap(0, 1)
+			case actionLeft:
+				a.panMap(-1, 0)
+			case actionRight:
+				a.panMap(1, 0)
+			case actionZoomIn:
+				a.zoomMap(1)
+			case actionZoomOut:
+				a.zoomMap(-1)
+			case actionMap:
+				a.toggleMap()
+			default:
+				if e.Keysym.Scancode == sdl.SCANCODE_ESCAPE {
+					a.toggleMap()
+				}
+			}
+		}
+
+	case controlsState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			if e.Keysym.Scancode == sdl.SCANCODE_ESCAPE || a.bindings[e.Keysym.Scancode] == actionControls {
+				a.toggleControls()
+			}
+		}
+
+	case targetingState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			shift := e.Keysym.Mod&sdl.KMOD_SHIFT != 0
+
+			switch a.bindings[e.Keysym.Scancode] {
+			case actionUp:
+				a.target.Y--
+			case actionDown:
+				a.target.Y++
+			case actionLeft:
+				a.target.X--
+			case actionRight:
+				a.target.X++
+			}
+
+			switch e.Keysym.Scancode {
+			case sdl.SCANCODE_TAB:
+				if shift {
+					a.cycleTarget(-1)
+				} else {
+					a.cycleTarget(1)
+				}
+			case sdl.SCANCODE_F, sdl.SCANCODE_RETURN:
+				a.state = mainState
+				a.queueInput(a.targetInput())
+			case sdl.SCANCODE_ESCAPE:
+				a.state = mainState
+			default:
+				// do nothing
+			}
+		}
+
+	case editorState:
+		if e.Type == sdl.KEYDOWN && e.Repeat == 0 {
+			ctrl := e.Keysym.Mod&sdl.KMOD_CTRL != 0
+
+			switch e.Keysym.Scancode {
+			case sdl.SCANCODE_E, sdl.SCANCODE_ESCAPE:
+				a.toggleEditor()
+			case sdl.SCANCODE_UP:
+				a.centerY--
+			case sdl.SCANCODE_DOWN:
+				a.centerY++
+			case sdl.SCANCODE_LEFT:
+				a.centerX--
+			case sdl.SCANCODE_RIGHT:
+				a.centerX++
+			case sdl.SCANCODE_EQUALS, sdl.SCANCODE_KP_PLUS:
+				a.zoomCamera(1)
+			case sdl.SCANCODE_MINUS, sdl.SCANCODE_KP_MINUS:
+				a.zoomCamera(-1)
+			case sdl.SCANCODE_TAB:
+				a.cyclePortalTarget()
+			case sdl.SCANCODE_Z:
+				if ctrl {
+					a.undoEdit()
+				}
+			case sdl.SCANCODE_Y:
+				if ctrl {
+					a.redoEdit()
+				}
+			case sdl.SCANCODE_S:
+				if ctrl {
+					a.saveLevel()
+				}
+			default:
+				// do nothing
+			}
+		}
+	}
+}
+
+// doAction sends the game input for an action on the main screen, or opens
+// the screen it leads to
+func (a *App) doAction(action keyAction) {
+	input := game.Input{
+		Type: game.None,
+	}
+
+	switch action {
+	case actionUp:
+		input.Type = game.Up
+	case actionDown:
+		input.Type = game.Down
+	case actionLeft:
+		input.Type = game.Left
+	case actionRight:
+		input.Type = game.Right
+	case actionInventory:
+		a.toggleInventory()
+	case actionTakeAll:
+		input.Type = game.TakeAll
+	case actionCloseDoor:
+		input.Type = game.CloseDoor
+	case actionSearch:
+		input.Type = game.Search
+	case actionFire:
+		if !a.startTargeting() {
+			input.Type = game.Fire
+			input.Target = a.loadedLevel.Player.Pos
+		}
+	case actionEditor:
+		a.toggleEditor()
+	case actionJournal:
+		a.toggleJournal()
+	case actionMap:
+		a.toggleMap()
+	case actionLog:
+		a.toggleLog()
+	case actionControls:
+		a.toggleControls()
+	case actionZoomIn:
+		a.zoomCamera(1)
+	case actionZoomOut:
+		a.zoomCamera(-1)
+	case actionAbility1, actionAbility2, actionAbility3,
+		actionAbility4, actionAbility5, actionAbility6,
+		actionAbility7, actionAbility8, actionAbility9:
+		castInput := a.useAbility(int(action - actionAbility1))
+		if castInput != nil {
+			input = *castInput
+		}
+	default:
+		// do nothing
+	}
+
+	a.queueInput(&input)
+}
@@ -0,0 +1,255 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// keysFile holds the player's key and gamepad bindings, one action per line:
+//
+//	up = Up, W, K, Keypad 8, pad:dpup
+//
+// Keys use SDL scancode names and gamepad buttons use SDL controller button
+// names after "pad:". Actions listed in the file replace their default keys
+// and buttons, anything not listed keeps its defaults.
+const keysFile = "internal/ui/assets/keys.txt"
+
+// padPrefix marks a gamepad button in the bindings file
+const padPrefix = "pad:"
+
+// keyAction is something the player can do from the main game screen
+type keyAction int
+
+const (
+	actionNone keyAction = iota
+	actionUp
+	actionDown
+	actionLeft
+	actionRight
+	actionInventory
+	actionTakeAll
+	actionCloseDoor
+	actionSearch
+	actionFire
+	actionJournal
+	actionMap
+	actionLog
+	actionEditor
+	actionControls
+	actionFullscreen
+	actionStats
+	actionZoomIn
+	actionZoomOut
+	actionAbility1
+	actionAbility2
+	actionAbility3
+	actionAbility4
+	actionAbility5
+	actionAbility6
+	actionAbility7
+	actionAbility8
+	actionAbility9
+)
+
+// keyActionInfo describes a bindable action
+type keyActionInfo struct {
+	name        string
+	description string
+	repeats     bool
+	defaults    []sdl.Scancode
+}
+
+var keyActions = []keyActionInfo{
+	actionUp: {"up", "Move up", true, []sdl.Scancode{
+		sdl.SCANCODE_UP, sdl.SCANCODE_W, sdl.SCANCODE_K, sdl.SCANCODE_KP_8}},
+	actionDown: {"down", "Move down", true, []sdl.Scancode{
+		sdl.SCANCODE_DOWN, sdl.SCANCODE_S, sdl.SCANCODE_J, sdl.SCANCODE_KP_2}},
+	actionLeft: {"left", "Move left", true, []sdl.Scancode{
+		sdl.SCANCODE_LEFT, sdl.SCANCODE_A, sdl.SCANCODE_H, sdl.SCANCODE_KP_4}},
+	actionRight: {"right", "Move right", true, []sdl.Scancode{
+		sdl.SCANCODE_RIGHT, sdl.SCANCODE_D, sdl.SCANCODE_L, sdl.SCANCODE_KP_6}},
+	actionInventory:  {"inventory", "Inventory", false, []sdl.Scancode{sdl.SCANCODE_I}},
+	actionTakeAll:    {"take", "Take all", false, []sdl.Scancode{sdl.SCANCODE_T, sdl.SCANCODE_G}},
+	actionCloseDoor:  {"close", "Close door", false, []sdl.Scancode{sdl.SCANCODE_C}},
+	actionSearch:     {"search", "Search", true, []sdl.Scancode{sdl.SCANCODE_PERIOD, sdl.SCANCODE_KP_5}},
+	actionFire:       {"fire", "Fire", false, []sdl.Scancode{sdl.SCANCODE_F}},
+	actionJournal:    {"journal", "Quest journal", false, []sdl.Scancode{sdl.SCANCODE_Q}},
+	actionLog:        {"log", "Message log", false, []sdl.Scancode{sdl.SCANCODE_P}},
+	actionMap:        {"map", "Map", false, []sdl.Scancode{sdl.SCANCODE_M, sdl.SCANCODE_TAB}},
+	actionEditor:     {"editor", "Level editor", false, []sdl.Scancode{sdl.SCANCODE_E}},
+	actionControls:   {"controls", "Controls", false, []sdl.Scancode{sdl.SCANCODE_F1, sdl.SCANCODE_SLASH}},
+	actionFullscreen: {"fullscreen", "Fullscreen", false, []sdl.Scancode{sdl.SCANCODE_F11}},
+	actionStats:      {"stats", "Frame stats", false, []sdl.Scancode{sdl.SCANCODE_F3}},
+	actionZoomIn:     {"zoomin", "Zoom in", true, []sdl.Scancode{sdl.SCANCODE_EQUALS, sdl.SCANCODE_KP_PLUS}},
+	actionZoomOut:    {"zoomout", "Zoom out", true, []sdl.Scancode{sdl.SCANCODE_MINUS, sdl.SCANCODE_KP_MINUS}},
+	actionAbility1:   {"ability1", "Ability 1", false, []sdl.Scancode{sdl.SCANCODE_1}},
+	actionAbility2:   {"ability2", "Ability 2", false, []sdl.Scancode{sdl.SCANCODE_2}},
+	actionAbility3:   {"ability3", "Ability 3", false, []sdl.Scancode{sdl.SCANCODE_3}},
+	actionAbility4:   {"ability4", "Ability 4", false, []sdl.Scancode{sdl.SCANCODE_4}},
+	actionAbility5:   {"ability5", "Ability 5", false, []sdl.Scancode{sdl.SCANCODE_5}},
+	actionAbility6:   {"ability6", "Ability 6", false, []sdl.Scancode{sdl.SCANCODE_6}},
+	actionAbility7:   {"ability7", "Ability 7", false, []sdl.Scancode{sdl.SCANCODE_7}},
+	actionAbility8:   {"ability8", "Ability 8", false, []sdl.Scancode{sdl.SCANCODE_8}},
+	actionAbility9:   {"ability9", "Ability 9", false, []sdl.Scancode{sdl.SCANCODE_9}},
+}
+
+// padDefaults holds the gamepad buttons bound to each action by default
+var padDefaults = map[keyAction][]uint8{
+	actionUp:        {sdl.CONTROLLER_BUTTON_DPAD_UP},
+	actionDown:      {sdl.CONTROLLER_BUTTON_DPAD_DOWN},
+	actionLeft:      {sdl.CONTROLLER_BUTTON_DPAD_LEFT},
+	actionRight:     {sdl.CONTROLLER_BUTTON_DPAD_RIGHT},
+	actionTakeAll:   {sdl.CONTROLLER_BUTTON_A},
+	actionSearch:    {sdl.CONTROLLER_BUTTON_B},
+	actionFire:      {sdl.CONTROLLER_BUTTON_X},
+	actionInventory: {sdl.CONTROLLER_BUTTON_Y},
+	actionJournal:   {sdl.CONTROLLER_BUTTON_BACK},
+	actionControls:  {sdl.CONTROLLER_BUTTON_START},
+	actionAbility1:  {sdl.CONTROLLER_BUTTON_LEFTSHOULDER},
+	actionAbility2:  {sdl.CONTROLLER_BUTTON_RIGHTSHOULDER},
+}
+
+// loadKeyBindings builds the scancode to action table from the defaults and
+// the bindings file, if there is one, along with the gamepad button table
+func loadKeyBindings(filename string) (map[sdl.Scancode]keyAction, map[uint8]keyAction) {
+	keys := make([][]sdl.Scancode, len(keyActions))
+	buttons := make([][]uint8, len(keyActions))
+	for action, info := range keyActions {
+		keys[action] = info.defaults
+		buttons[action] = padDefaults[keyAction(action)]
+	}
+
+	file, err := os.Open(filename)
+	if err == nil {
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			action, scancodes, padButtons, err := parseKeyBinding(line)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Ignoring key binding: %s\n", err)
+				continue
+			}
+			keys[action] = scancodes
+			buttons[action] = padButtons
+		}
+	} else if !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Failed to open key bindings: %s\n", err)
+	}
+
+	bindings := make(map[sdl.Scancode]keyAction)
+	for action, scancodes := range keys {
+		for _, scancode := range scancodes {
+			if other, exists := bindings[scancode]; exists {
+				fmt.Fprintf(os.Stderr, "%s is bound to both %s and %s\n",
+					sdl.GetScancodeName(scancode), keyActions[other].name, keyActions[action].name)
+			}
+			bindings[scancode] = keyAction(action)
+		}
+	}
+
+	padBindings := make(map[uint8]keyAction)
+	for action, padButtons := range buttons {
+		for _, button := range padButtons {
+			padBindings[button] = keyAction(action)
+		}
+	}
+
+	return bindings, padBindings
+}
+
+// parseKeyBinding parses a line written as "action = key, key, pad:button"
+func parseKeyBinding(line string) (keyAction, []sdl.Scancode, []uint8, error) {
+	sep := strings.Index(line, "=")
+	if sep == -1 {
+		return actionNone, nil, nil, fmt.Errorf("expected action = keys: %s", line)
+	}
+
+	name := strings.TrimSpace(line[:sep])
+	action := actionNone
+	for i, info := range keyActions {
+		if info.name == name {
+			action = keyAction(i)
+		}
+	}
+	if action == actionNone {
+		return actionNone, nil, nil, fmt.Errorf("unknown action: %s", name)
+	}
+
+	scancodes := make([]sdl.Scancode, 0)
+	buttons := make([]uint8, 0)
+	for _, key := range strings.Split(line[sep+1:], ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		if strings.HasPrefix(key, padPrefix) {
+			button := sdl.GameControllerGetButtonFromString(strings.TrimPrefix(key, padPrefix))
+			if button == sdl.CONTROLLER_BUTTON_INVALID {
+				return actionNone, nil, nil, fmt.Errorf("unknown gamepad button: %s", key)
+			}
+			buttons = append(buttons, uint8(button))
+			continue
+		}
+
+		scancode := sdl.GetScancodeFromName(key)
+		if scancode == sdl.SCANCODE_UNKNOWN {
+			return actionNone, nil, nil, fmt.Errorf("unknown key: %s", key)
+		}
+		scancodes = append(scancodes, scancode)
+	}
+
+	return action, scancodes, buttons, nil
+}
+
+// boundAction returns the action a key press triggers, ignoring repeats of
+// actions that shouldn't repeat when the key is held
+func (a *App) boundAction(e *sdl.KeyboardEvent) keyAction {
+	action := a.bindings[e.Keysym.Scancode]
+	if e.Repeat != 0 && !keyActions[action].repeats {
+		return actionNone
+	}
+
+	return action
+}
+
+// keysFor returns the names of the keys bound to an action
+func (a *App) keysFor(action keyAction) []string {
+	scancodes := make([]sdl.Scancode, 0)
+	for scancode, bound := range a.bindings {
+		if bound == action {
+			scancodes = append(scancodes, scancode)
+		}
+	}
+	sort.Slice(scancodes, func(i, j int) bool { return scancodes[i] < scancodes[j] })
+
+	names := make([]string, len(scancodes))
+	for i, scancode := range scancodes {
+		names[i] = sdl.GetScancodeName(scancode)
+	}
+
+	buttons := make([]uint8, 0)
+	for button, bound := range a.padBindings {
+		if bound == action {
+			buttons = append(buttons, button)
+		}
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+
+	for _, button := range buttons {
+		names = append(names, padPrefix+sdl.GameControllerGetStringForButton(sdl.GameControllerButton(button)))
+	}
+
+	return names
+}
@@ -0,0 +1,242 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// logLines is how many of the latest messages the event log shows
+const logLines = 8
+
+// maxMessages is how many of the ui's own messages are kept
+const maxMessages = 100
+
+// categoryColors colours messages in the log by their category
+var categoryColors = []sdl.Color{
+	game.GeneralMessages: {R: 210, G: 210, B: 210},
+	game.CombatMessages:  {R: 255, G: 80, B: 80},
+	game.ItemMessages:    {R: 255, G: 220, B: 100},
+	game.QuestMessages:   {R: 120, G: 200, B: 255},
+}
+
+var hiddenCategoryColor = sdl.Color{R: 100, G: 100, B: 100}
+
+// addMessage adds a line of the ui's own to the message log. The game's log
+// belongs to the game, so these are kept apart and merged in when drawn.
+func (a *App) addMessage(text string) {
+	turn := 0
+	if a.loadedLevel.Log != nil {
+		turn = a.loadedLevel.Log.Turn
+	}
+
+	if n := len(a.messages); n > 0 && a.messages[n-1].Text == text && a.messages[n-1].Turn == turn {
+		a.messages[n-1].Count++
+		return
+	}
+
+	a.messages = append(a.messages, &game.LogEntry{
+		Turn:     turn,
+		Text:     text,
+		Category: game.GeneralMessages,
+		Count:    1,
+	})
+	if len(a.messages) > maxMessages {
+		a.messages = a.messages[len(a.messages)-maxMessages:]
+	}
+}
+
+// getLog returns the game's messages and the ui's own in the order of the
+// turns they were logged on, oldest first
+func (a *App) getLog() []*game.LogEntry {
+	var entries []*game.LogEntry
+	if a.loadedLevel.Log != nil {
+		entries = a.loadedLevel.Log.Entries
+	}
+
+	log := make([]*game.LogEntry, 0, len(entries)+len(a.messages))
+	messages := a.messages
+	for _, entry := range entries {
+		for len(messages) > 0 && messages[0].Turn < entry.Turn {
+			log = append(log, messages[0])
+			messages = messages[1:]
+		}
+		log = append(log, entry)
+	}
+
+	return append(log, messages...)
+}
+
+func (a *App) toggleLog() {
+	if a.state == mainState {
+		a.logScroll = 0
+		a.state = logState
+	} else if a.state == logState {
+		a.state = mainState
+	}
+}
+
+// toggleLogFilter shows or hides a category of messages on the log screen
+func (a *App) toggleLogFilter(category int) {
+	if category < 0 || category >= len(game.MessageCategories) {
+		return
+	}
+
+	if a.logHidden == nil {
+		a.logHidden = make(map[game.MessageCategory]bool)
+	}
+	a.logHidden[game.MessageCategory(category)] = !a.logHidden[game.MessageCategory(category)]
+	a.logScroll = 0
+}
+
+// filteredLog returns the messages shown on the log screen, oldest first
+func (a *App) filteredLog() []*game.LogEntry {
+	entries := make([]*game.LogEntry, 0)
+	for _, entry := range a.getLog() {
+		if !a.logHidden[entry.Category] {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// getLogPageLines returns how many messages fit on the log screen
+func (a *App) getLogPageLines() int {
+	_, lineHeight, _ := a.smallFont.SizeUTF8("A")
+	_, titleHeight, _ := a.mediumFont.SizeUTF8("A")
+
+	return int(a.getInventoryBackdropRect().H-24-int32(titleHeight+lineHeight)) / lineHeight
+}
+
+// scrollLog moves the log screen back through older messages, a scroll of 0
+// shows the latest
+func (a *App) scrollLog(lines int) {
+	a.logScroll += lines
+
+	maxScroll := len(a.filteredLog()) - a.getLogPageLines()
+	if a.logScroll > maxScroll {
+		a.logScroll = maxScroll
+	}
+	if a.logScroll < 0 {
+		a.logScroll = 0
+	}
+}
+
+// handleLogKey scrolls and filters the log screen, scrolling repeats while
+// keys are held
+func (a *App) handleLogKey(e *sdl.KeyboardEvent) {
+	page := a.getLogPageLines()
+	repeat := e.Repeat != 0
+
+	switch e.Keysym.Scancode {
+	case sdl.SCANCODE_ESCAPE:
+		if !repeat {
+			a.toggleLog()
+		}
+	case sdl.SCANCODE_PAGEUP:
+		a.scrollLog(page)
+	case sdl.SCANCODE_PAGEDOWN:
+		a.scrollLog(-page)
+	case sdl.SCANCODE_HOME:
+		a.scrollLog(len(a.filteredLog()))
+	case sdl.SCANCODE_END:
+		a.logScroll = 0
+	case sdl.SCANCODE_1, sdl.SCANCODE_2, sdl.SCANCODE_3, sdl.SCANCODE_4:
+		if !repeat {
+			a.toggleLogFilter(int(e.Keysym.Scancode - sdl.SCANCODE_1))
+		}
+	case sdl.SCANCODE_0:
+		a.logHidden = nil
+		a.logScroll = 0
+	default:
+		switch a.boundAction(e) {
+		case actionUp:
+			a.scrollLog(1)
+		case actionDown:
+			a.scrollLog(-1)
+		case actionLog:
+			a.toggleLog()
+		}
+	}
+}
+
+// drawEventLog draws the latest messages in the corner of the screen
+func (a *App) drawEventLog() {
+	textStart := int32(float64(a.height) * 0.75)
+	a.copy(a.eventBackground, nil, &sdl.Rect{
+		X: 0,
+		Y: textStart,
+		W: int32(float64(a.width) * 0.25),
+		H: int32(float64(a.height) * 0.75),
+	})
+
+	entries := a.getLog()
+	if len(entries) > logLines {
+		entries = entries[len(entries)-logLines:]
+	}
+
+	_, fontSizeY, _ := a.smallFont.SizeUTF8("A")
+	for i, entry := range entries {
+		a.drawLogText(entry.String(), categoryColors[entry.Category], 0, int32(i*fontSizeY)+textStart)
+	}
+}
+
+// drawLog draws the message history with the turn each message was logged on
+func (a *App) drawLog() {
+	logRect := a.getInventoryBackdropRect()
+	a.copy(a.inventoryBackground, nil, logRect)
+
+	x, y := logRect.X+8, logRect.Y+8
+
+	tex := a.stringToTexture("Messages", mediumFont, textColor)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: x, Y: y, W: w, H: h})
+		y += h
+	}
+
+	// list the categories, greyed out when hidden
+	filterX := x
+	for i, name := range game.MessageCategories {
+		color := categoryColors[i]
+		if a.logHidden[game.MessageCategory(i)] {
+			color = hiddenCategoryColor
+		}
+
+		w, _ := a.drawLogText(strconv.Itoa(i+1)+" "+name, color, filterX, y)
+		filterX += w + 16
+	}
+	_, lineHeight, _ := a.smallFont.SizeUTF8("A")
+	y += int32(lineHeight) + 8
+
+	entries := a.filteredLog()
+	end := len(entries) - a.logScroll
+	if end < 0 {
+		end = 0
+	}
+	start := end - a.getLogPageLines()
+	if start < 0 {
+		start = 0
+	}
+
+	for _, entry := range entries[start:end] {
+		line := strconv.Itoa(entry.Turn) + ": " + entry.String()
+		_, h := a.drawLogText(line, categoryColors[entry.Category], x, y)
+		y += h
+	}
+}
+
+// drawLogText draws a line of the log in a colour, returning its size
+func (a *App) drawLogText(s string, color sdl.Color, x int32, y int32) (int32, int32) {
+	tex := a.stringToTexture(s, smallFont, color)
+	_, _, w, h, err := tex.Query()
+	if err != nil {
+		return 0, 0
+	}
+
+	a.copy(tex, nil, &sdl.Rect{X: x, Y: y, W: w, H: h})
+
+	return w, h
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// mapZoomLevels are the sizes in pixels a tile can be drawn at on the full
+// screen map
+var mapZoomLevels = []int32{2, 4, 6, 8, 12, 16}
+
+const defaultMapZoom = 2
+
+// the minimap fills this much of the window width, and shows tiles at most
+// this many pixels across
+const (
+	minimapSizeRatio = 0.15
+	minimapMaxTile   = 6
+)
+
+var (
+	mapStoneColor   = sdl.Color{R: 110, G: 110, B: 110, A: 255}
+	mapFloorColor   = sdl.Color{R: 60, G: 50, B: 40, A: 255}
+	mapDoorColor    = sdl.Color{R: 149, G: 84, B: 19, A: 255}
+	mapPlayerColor  = sdl.Color{R: 255, G: 255, B: 0, A: 255}
+	mapMonsterColor = sdl.Color{R: 255, G: 0, B: 0, A: 255}
+	mapNPCColor     = sdl.Color{R: 0, G: 255, B: 0, A: 255}
+	mapPortalColor  = sdl.Color{R: 0, G: 128, B: 255, A: 255}
+)
+
+func (a *App) toggleMap() {
+	if a.state == mainState {
+		a.mapX = a.loadedLevel.Player.X
+		a.mapY = a.loadedLevel.Player.Y
+		a.state = mapState
+	} else if a.state == mapState {
+		a.state = mainState
+	}
+}
+
+// panMap moves the full screen map, keeping its center on the level
+func (a *App) panMap(dx int, dy int) {
+	a.mapX += dx
+	a.mapY += dy
+
+	if a.mapY < 0 {
+		a.mapY = 0
+	}
+	if a.mapY >= len(a.loadedLevel.Tiles) {
+		a.mapY = len(a.loadedLevel.Tiles) - 1
+	}
+	if a.mapX < 0 {
+		a.mapX = 0
+	}
+	if a.mapY >= 0 && a.mapX >= len(a.loadedLevel.Tiles[a.mapY]) {
+		a.mapX = len(a.loadedLevel.Tiles[a.mapY]) - 1
+	}
+}
+
+// zoomMap steps the full screen map zoom in or out
+func (a *App) zoomMap(step int) {
+	a.mapZoom += step
+	if a.mapZoom < 0 {
+		a.mapZoom = 0
+	}
+	if a.mapZoom >= len(mapZoomLevels) {
+		a.mapZoom = len(mapZoomLevels) - 1
+	}
+}
+
+func (a *App) getMinimapRect() *sdl.Rect {
+	size := int32(float64(a.width) * minimapSizeRatio)
+
+	return &sdl.Rect{
+		X: a.width - size - 8,
+		Y: 8,
+		W: size,
+		H: size,
+	}
+}
+
+// drawMinimap draws the explored part of the level in the corner of the
+// screen, centered on the player
+func (a *App) drawMinimap() {
+	minimapRect := a.getMinimapRect()
+
+	// fit the whole level in the minimap when it's small enough
+	tileSize := int32(minimapMaxTile)
+	for _, size := range []int32{int32(len(a.loadedLevel.Tiles)), a.getLevelWidth()} {
+		if size > 0 && minimapRect.W/size < tileSize {
+			tileSize = minimapRect.W / size
+		}
+	}
+	if tileSize < 2 {
+		tileSize = 2
+	}
+
+	a.copy(a.eventBackground, nil, minimapRect)
+	a.drawMapView(minimapRect, tileSize, a.loadedLevel.Player.X, a.loadedLevel.Player.Y)
+}
+
+// drawMap draws the explored part of the level across the whole screen
+func (a *App) drawMap() {
+	mapRect := &sdl.Rect{X: 0, Y: 0, W: a.width, H: a.height}
+	a.copy(a.slotBackground, nil, mapRect)
+	a.drawMapView(mapRect, mapZoomLevels[a.mapZoom], a.mapX, a.mapY)
+
+	tex := a.stringToTexture(a.game.LevelName(a.loadedLevel), mediumFont, textColor)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: (a.width - w) / 2, Y: 8, W: w, H: h})
+	}
+}
+
+// drawMapView draws the tiles the player has seen inside rect, with the given
+// tile centered, marking the player, visible monsters and npcs, stairs and
+// portals
+func (a *App) drawMapView(rect *sdl.Rect, tileSize int32, centerX int, centerY int) {
+	level := a.loadedLevel
+	originX := rect.X + rect.W/2 - int32(centerX)*tileSize - tileSize/2
+	originY := rect.Y + rect.H/2 - int32(centerY)*tileSize - tileSize/2
+
+	a.renderer.SetClipRect(rect)
+	defer a.renderer.SetClipRect(nil)
+
+	mark := func(pos game.Pos, color sdl.Color, dim bool) {
+		if dim {
+			color.R, color.G, color.B = color.R/2, color.G/2, color.B/2
+		}
+		a.renderer.SetDrawColor(color.R, color.G, color.B, color.A)
+		a.fillRect(&sdl.Rect{
+			X: originX + int32(pos.X)*tileSize,
+			Y: originY + int32(pos.Y)*tileSize,
+			W: tileSize,
+			H: tileSize,
+		})
+	}
+
+	for y, row := range level.Tiles {
+		for x, tile := range row {
+			if !tile.Seen || tile.Symbol == game.EmptyTile {
+				continue
+			}
+
+			pos := game.Pos{X: x, Y: y}
+			color := mapFloorColor
+			switch {
+			case level.Portals[pos] != nil:
+				color = mapPortalColor
+			case tile.OverlaySymbol == game.UpStairTile || tile.OverlaySymbol == game.DownStairTile:
+				color = mapPortalColor
+			case tile.OverlaySymbol == game.ClosedDoorTile || tile.OverlaySymbol == game.OpenedDoorTile ||
+				tile.OverlaySymbol == game.LockedDoorTile:
+				color = mapDoorColor
+			case tile.Symbol == game.StoneTile || tile.OverlaySymbol == game.SecretDoorTile:
+				color = mapStoneColor
+			}
+			mark(pos, color, !tile.Visible)
+		}
+	}
+
+	for pos := range level.NPCs {
+		if level.Tiles[pos.Y][pos.X].Visible {
+			mark(pos, mapNPCColor, false)
+		}
+	}
+	for pos := range level.Monsters {
+		if level.Tiles[pos.Y][pos.X].Visible {
+			mark(pos, mapMonsterColor, false)
+		}
+	}
+	mark(level.Player.Pos, mapPlayerColor, false)
+
+	a.renderer.SetDrawColor(0, 0, 0, 255)
+}
+
+// getLevelWidth returns the width in tiles of the widest row of the level
+func (a *App) getLevelWidth() int32 {
+	width := 0
+	for _, row := range a.loadedLevel.Tiles {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	return int32(width)
+}
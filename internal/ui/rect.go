@@ -38,15 +38,81 @@ func (a *App) getInventoryBackdropRect() *sdl.Rect {
 	}
 }
 
-func (a *App) getInventoryItemRect(i int) *sdl.Rect {
+// getInventoryColumns returns how many items go across the inventory grid
+func (a *App) getInventoryColumns() int {
+	columns := int(a.getInventoryBackdropRect().W / a.getItemSize())
+	if columns > inventoryColumns {
+		columns = inventoryColumns
+	}
+
+	return columns
+}
+
+// getInventoryItemRect returns the rect of an inventory grid cell, scrolled by
+// the current inventory scroll, and whether that cell is on screen
+func (a *App) getInventoryItemRect(i int) (*sdl.Rect, bool) {
 	inventoryRect := a.getInventoryBackdropRect()
 	itemSize := a.getItemSize()
+	columns := a.getInventoryColumns()
+
+	row := i/columns - a.inventoryScroll
+	gridY := inventoryRect.Y + inventoryRect.H - inventoryRows*itemSize
 
 	return &sdl.Rect{
-		X: inventoryRect.X + int32(i)*itemSize,
-		Y: inventoryRect.Y + inventoryRect.H - itemSize,
+		X: inventoryRect.X + int32(i%columns)*itemSize,
+		Y: gridY + int32(row)*itemSize,
 		W: itemSize,
 		H: itemSize,
+	}, row >= 0 && row < inventoryRows
+}
+
+func (a *App) getPaletteSlotRect(i int) *sdl.Rect {
+	slotSize := a.getSlotSize()
+	perRow := int((a.width - 8) / (slotSize + 8))
+	if perRow > len(editorPalette) {
+		perRow = len(editorPalette)
+	}
+
+	paletteWidth := int32(perRow) * (slotSize + 8)
+	offsetX := (a.width - paletteWidth) / 2
+
+	return &sdl.Rect{
+		X: offsetX + int32(i%perRow)*(slotSize+8),
+		Y: 8 + int32(i/perRow)*(slotSize+8),
+		W: slotSize,
+		H: slotSize,
+	}
+}
+
+func (a *App) getHotbarSlotRect(i int) *sdl.Rect {
+	itemSize := a.getItemSize()
+	slotWidth := itemSize * 3
+	slots := int32(len(a.loadedLevel.Player.Abilities))
+	if slots > maxHotbarSlots {
+		slots = maxHotbarSlots
+	}
+	offsetX := (a.width - slots*(slotWidth+8)) / 2
+
+	return &sdl.Rect{
+		X: offsetX + int32(i)*(slotWidth+8),
+		Y: a.height - itemSize - 8,
+		W: slotWidth,
+		H: itemSize,
+	}
+}
+
+// getTradeRowRect returns the rect of a row in the trade screen, with row -1
+// being the column header
+func (a *App) getTradeRowRect(side int, i int) *sdl.Rect {
+	inventoryRect := a.getInventoryBackdropRect()
+	itemSize := a.getItemSize()
+	columnWidth := inventoryRect.W / 2
+
+	return &sdl.Rect{
+		X: inventoryRect.X + int32(side)*columnWidth + 8,
+		Y: inventoryRect.Y + 8 + int32(i+1)*itemSize,
+		W: columnWidth - 16,
+		H: itemSize,
 	}
 }
 
@@ -73,3 +139,42 @@ func (a *App) getArmorSlotRect() *sdl.Rect {
 		H: slotSize,
 	}
 }
+
+func (a *App) getDialogueRect() *sdl.Rect {
+	dialogueWidth := a.width * 2 / 3
+	dialogueHeight := a.height / 3
+
+	return &sdl.Rect{
+		X: (a.width - dialogueWidth) / 2,
+		Y: a.height - dialogueHeight - a.getItemSize() - 16,
+		W: dialogueWidth,
+		H: dialogueHeight,
+	}
+}
+
+// getDialogueRowRect returns the rect of a reply, counting up from the bottom
+// of the dialogue box so the conversation text has the space above
+func (a *App) getDialogueRowRect(i int) *sdl.Rect {
+	dialogueRect := a.getDialogueRect()
+	itemSize := a.getItemSize() / 2
+	rows := int32(len(a.loadedLevel.Choices()))
+
+	return &sdl.Rect{
+		X: dialogueRect.X + 16,
+		Y: dialogueRect.Y + dialogueRect.H - 8 - (rows-int32(i))*itemSize,
+		W: dialogueRect.W - 32,
+		H: itemSize,
+	}
+}
+
+func (a *App) getInspectionRect() *sdl.Rect {
+	inventoryRect := a.getInventoryBackdropRect()
+	panelWidth := (a.width-inventoryRect.W)/2 - 16
+
+	return &sdl.Rect{
+		X: a.width - panelWidth - 8,
+		Y: inventoryRect.Y,
+		W: panelWidth,
+		H: inventoryRect.H / 2,
+	}
+}
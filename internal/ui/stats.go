@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// statsInterval is how often the numbers on the stats overlay change, so they
+// can be read
+const statsInterval = 500 * time.Millisecond
+
+// frameStats measures how long frames and turns take for the stats overlay
+type frameStats struct {
+	drawCalls   int
+	frames      int
+	frameTime   time.Duration
+	drawTime    time.Duration
+	lastFrame   time.Time
+	turnSent    time.Time
+	turnLatency time.Duration
+
+	// what the overlay shows, refreshed every statsInterval
+	lines   []string
+	updated time.Time
+}
+
+// copy draws a texture, counting the draw call for the stats overlay
+func (a *App) copy(tex *sdl.Texture, src *sdl.Rect, dst *sdl.Rect) {
+	a.stats.drawCalls++
+	a.renderer.Copy(tex, src, dst)
+}
+
+// fillRect fills a rect with the draw color, counting the draw call
+func (a *App) fillRect(rect *sdl.Rect) {
+	a.stats.drawCalls++
+	a.renderer.FillRect(rect)
+}
+
+// drawRect outlines a rect with the draw color, counting the draw call
+func (a *App) drawRect(rect *sdl.Rect) {
+	a.stats.drawCalls++
+	a.renderer.DrawRect(rect)
+}
+
+func (a *App) toggleStats() {
+	a.showStats = !a.showStats
+	a.stats.updated = time.Time{}
+}
+
+// startFrame resets the per frame counts before drawing
+func (a *App) startFrame() {
+	a.stats.drawCalls = 0
+}
+
+// endFrame records how long the frame took, start being when drawing began
+func (a *App) endFrame(start time.Time) {
+	now := time.Now()
+	a.stats.drawTime = now.Sub(start)
+	if !a.stats.lastFrame.IsZero() {
+		a.stats.frameTime = now.Sub(a.stats.lastFrame)
+	}
+	a.stats.lastFrame = now
+	a.stats.frames++
+}
+
+// startTurn notes when an input was handed to the game
+func (a *App) startTurn() {
+	a.stats.turnSent = time.Now()
+}
+
+// endTurn records how long the game took to hand the level back
+func (a *App) endTurn() {
+	a.stats.turnLatency = time.Since(a.stats.turnSent)
+}
+
+// updateStats refreshes the overlay's numbers once every statsInterval,
+// returning whether they changed
+func (a *App) updateStats() bool {
+	if !a.showStats {
+		return false
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(a.stats.updated)
+	if elapsed < statsInterval {
+		return false
+	}
+
+	fps := 0.0
+	if !a.stats.updated.IsZero() {
+		fps = float64(a.stats.frames) / elapsed.Seconds()
+	}
+	a.stats.frames = 0
+	a.stats.updated = now
+
+	a.stats.lines = []string{
+		fmt.Sprintf("FPS: %.0f", fps),
+		fmt.Sprintf("Frame: %.1f ms", milliseconds(a.stats.frameTime)),
+		fmt.Sprintf("Draw: %.1f ms", milliseconds(a.stats.drawTime)),
+		fmt.Sprintf("Draw calls: %d", a.stats.drawCalls),
+		fmt.Sprintf("Turn: %.1f ms", milliseconds(a.stats.turnLatency)),
+		fmt.Sprintf("Cached text: %d/%d", a.textCache.len(), maxCachedTexts),
+	}
+
+	return true
+}
+
+// drawStats draws the stats overlay in the top left corner
+func (a *App) drawStats() {
+	if !a.showStats {
+		return
+	}
+
+	var y int32 = 4
+	for _, line := range a.stats.lines {
+		w, h := a.getGlyphTextSize(line, smallFont)
+		a.renderer.SetDrawColor(0, 0, 0, 160)
+		a.fillRect(&sdl.Rect{X: 0, Y: y, W: w + 8, H: h})
+		a.renderer.SetDrawColor(0, 0, 0, 255)
+		a.drawGlyphText(line, smallFont, textColor, 255, 4, y)
+		y += h
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"sort"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+)
+
+// startTargeting enters targeting mode if the player has a ranged weapon
+// equipped, aiming at the nearest visible monster
+func (a *App) startTargeting() bool {
+	weapon := a.loadedLevel.Player.Weapon
+	if weapon == nil || weapon.Range == 0 {
+		return false
+	}
+
+	a.casting = -1
+	a.aimAtNearest()
+
+	return true
+}
+
+// startCasting enters targeting mode for one of the player's abilities
+func (a *App) startCasting(slot int) {
+	a.casting = slot
+	a.aimAtNearest()
+}
+
+func (a *App) aimAtNearest() {
+	a.state = targetingState
+	a.target = a.loadedLevel.Player.Pos
+
+	targets := a.visibleMonsters()
+	if len(targets) > 0 {
+		a.target = targets[0]
+	}
+}
+
+// targetRange returns the range of whatever is being aimed
+func (a *App) targetRange() int {
+	player := a.loadedLevel.Player
+
+	if a.casting >= 0 && a.casting < len(player.Abilities) {
+		return player.Abilities[a.casting].Range
+	}
+	if player.Weapon != nil {
+		return player.Weapon.Range
+	}
+
+	return 0
+}
+
+// targetInput returns the input that fires or casts at the current target
+func (a *App) targetInput() *game.Input {
+	if a.casting >= 0 {
+		return a.castInput(a.casting, a.target)
+	}
+
+	return &game.Input{
+		Type:   game.Fire,
+		Target: a.target,
+	}
+}
+
+func (a *App) castInput(slot int, target game.Pos) *game.Input {
+	return &game.Input{
+		Type:    game.CastAbility,
+		Ability: slot,
+		Target:  target,
+	}
+}
+
+// visibleMonsters returns the positions of the monsters the player can see,
+// nearest first
+func (a *App) visibleMonsters() []game.Pos {
+	level := a.loadedLevel
+	player := level.Player.Pos
+
+	positions := make([]game.Pos, 0)
+	for pos := range level.Monsters {
+		if level.Tiles[pos.Y][pos.X].Visible {
+			positions = append(positions, pos)
+		}
+	}
+
+	distance := func(pos game.Pos) int {
+		xDelta := pos.X - player.X
+		yDelta := pos.Y - player.Y
+		return xDelta*xDelta + yDelta*yDelta
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		di, dj := distance(positions[i]), distance(positions[j])
+		if di != dj {
+			return di < dj
+		}
+		if positions[i].Y != positions[j].Y {
+			return positions[i].Y < positions[j].Y
+		}
+		return positions[i].X < positions[j].X
+	})
+
+	return positions
+}
+
+func (a *App) cycleTarget(step int) {
+	targets := a.visibleMonsters()
+	if len(targets) == 0 {
+		return
+	}
+
+	index := -1
+	for i, pos := range targets {
+		if pos == a.target {
+			index = i
+		}
+	}
+
+	index = (index + step + len(targets)) % len(targets)
+	a.target = targets[index]
+}
+
+func (a *App) drawTargeting() {
+	level := a.loadedLevel
+	for _, pos := range level.LineOfFire(level.Player.Pos, a.target) {
+		a.copy(a.targetHighlight, nil, a.getTileRect(pos.X, pos.Y))
+	}
+
+	// outline the target, red when out of range
+	targetRange := a.targetRange()
+	xDelta := a.target.X - level.Player.X
+	yDelta := a.target.Y - level.Player.Y
+	if xDelta*xDelta+yDelta*yDelta <= targetRange*targetRange {
+		a.renderer.SetDrawColor(255, 255, 255, 255)
+	} else {
+		a.renderer.SetDrawColor(255, 0, 0, 255)
+	}
+
+	a.drawRect(a.getTileRect(a.target.X, a.target.Y))
+	a.renderer.SetDrawColor(0, 0, 0, 255)
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"container/list"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// maxCachedTexts is how many rendered strings are kept before the least
+// recently drawn are destroyed. It only needs to hold a few frames' worth of
+// text, textures are used straight after they are fetched.
+const maxCachedTexts = 256
+
+// textKey is what a rendered string is looked up by
+type textKey struct {
+	s     string
+	size  fontSize
+	color sdl.Color
+}
+
+type cachedText struct {
+	key textKey
+	tex *sdl.Texture
+}
+
+// textCache holds rendered strings, destroying the least recently used once
+// it is full
+type textCache struct {
+	capacity int
+	entries  map[textKey]*list.Element
+	order    *list.List
+}
+
+func newTextCache(capacity int) *textCache {
+	return &textCache{
+		capacity: capacity,
+		entries:  make(map[textKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns a cached string's texture, marking it as the most recently used
+func (cache *textCache) get(key textKey) (*sdl.Texture, bool) {
+	element, exists := cache.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+	return element.Value.(*cachedText).tex, true
+}
+
+// add caches a string's texture, destroying the least recently used ones past
+// the cache's capacity
+func (cache *textCache) add(key textKey, tex *sdl.Texture) {
+	cache.entries[key] = cache.order.PushFront(&cachedText{key: key, tex: tex})
+
+	for cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+
+		text := oldest.Value.(*cachedText)
+		delete(cache.entries, text.key)
+		text.tex.Destroy()
+	}
+}
+
+// clear destroys every cached texture
+func (cache *textCache) clear() {
+	for element := cache.order.Front(); element != nil; element = element.Next() {
+		element.Value.(*cachedText).tex.Destroy()
+	}
+
+	cache.entries = make(map[textKey]*list.Element)
+	cache.order.Init()
+}
+
+// len returns how many strings are cached
+func (cache *textCache) len() int {
+	return cache.order.Len()
+}
@@ -56,8 +56,14 @@ func (a *App) imgFileToTexture(filename string) *sdl.Texture {
 	return tex
 }
 
-func (a *App) loadTextureIndex(filename string) map[rune][]sdl.Rect {
+// loadTextureIndex reads where each glyph's sprite is in the texture atlas.
+// Each line is "glyph x,y,variations" where the variations of a tile follow
+// one another in the atlas. An optional fourth number gives the frames of an
+// animated sprite, which likewise follow the first frame. A sprite has either
+// variations or frames, not both.
+func (a *App) loadTextureIndex(filename string) (map[rune][]sdl.Rect, map[rune][]sdl.Rect) {
 	textureIndex := make(map[rune][]sdl.Rect)
+	frameIndex := make(map[rune][]sdl.Rect)
 
 	file, err := os.Open(filename)
 	if err != nil {
@@ -70,7 +76,7 @@ func (a *App) loadTextureIndex(filename string) map[rune][]sdl.Rect {
 		line = strings.TrimSpace(line)
 
 		tile := rune(line[0])
-		tileInfo := strings.Split(line[1:], ",") // x, y, variation
+		tileInfo := strings.Split(line[1:], ",") // x, y, variation, frames
 
 		x, err := strconv.ParseInt(strings.TrimSpace(tileInfo[0]), 10, 64)
 		if err != nil {
@@ -87,8 +93,21 @@ func (a *App) loadTextureIndex(filename string) map[rune][]sdl.Rect {
 			panic(err)
 		}
 
+		frames := int64(1)
+		if len(tileInfo) > 3 {
+			frames, err = strconv.ParseInt(strings.TrimSpace(tileInfo[3]), 10, 64)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		// variations and frames would share the same run of rects
+		if variation > 1 && frames > 1 {
+			panic("Sprite has both variations and frames: " + line)
+		}
+
 		rects := make([]sdl.Rect, 0)
-		for i := 0; i < int(variation); i++ {
+		for i := 0; i < int(variation*frames); i++ {
 			rects = append(rects, sdl.Rect{
 				X: int32(x * spriteHeight),
 				Y: int32(y * spriteHeight),
@@ -102,10 +121,14 @@ func (a *App) loadTextureIndex(filename string) map[rune][]sdl.Rect {
 			}
 		}
 
+		if frames > 1 {
+			frameIndex[tile] = rects
+			rects = rects[:1]
+		}
 		textureIndex[tile] = rects
 	}
 
-	return textureIndex
+	return textureIndex, frameIndex
 }
 
 type fontSize int
@@ -116,27 +139,28 @@ const (
 	largeFont
 )
 
-func (a *App) stringToTexture(s string, size fontSize, color sdl.Color) *sdl.Texture {
-	var font *ttf.Font
+// getFont returns the open font of a size
+func (a *App) getFont(size fontSize) *ttf.Font {
 	switch size {
-	case smallFont:
-		font = a.smallFont
-		if tex, exists := a.str2TexSmall[s]; exists {
-			return tex
-		}
 	case mediumFont:
-		font = a.mediumFont
-		if tex, exists := a.str2TexMedium[s]; exists {
-			return tex
-		}
+		return a.mediumFont
 	case largeFont:
-		font = a.largeFont
-		if tex, exists := a.str2TexLarge[s]; exists {
-			return tex
-		}
+		return a.largeFont
+	default:
+		return a.smallFont
 	}
+}
 
-	surface, err := font.RenderUTF8Blended(s, color)
+// stringToTexture returns a string rendered in a font size and color, drawing
+// it only if it isn't already cached. The texture may be destroyed once enough
+// other text has been drawn, so it should be used straight away.
+func (a *App) stringToTexture(s string, size fontSize, color sdl.Color) *sdl.Texture {
+	key := textKey{s: s, size: size, color: color}
+	if tex, exists := a.textCache.get(key); exists {
+		return tex
+	}
+
+	surface, err := a.getFont(size).RenderUTF8Blended(s, color)
 	if err != nil {
 		panic(err)
 	}
@@ -147,15 +171,7 @@ func (a *App) stringToTexture(s string, size fontSize, color sdl.Color) *sdl.Tex
 		panic(err)
 	}
 
-	switch size {
-	case smallFont:
-		a.str2TexSmall[s] = tex
-	case mediumFont:
-		a.str2TexMedium[s] = tex
-	case largeFont:
-		a.str2TexLarge[s] = tex
-	}
-
+	a.textCache.add(key, tex)
 	return tex
 }
 
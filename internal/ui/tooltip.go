@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var (
+	textColor   = sdl.Color{R: 255, G: 255, B: 255}
+	betterColor = sdl.Color{R: 0, G: 255, B: 0}
+	worseColor  = sdl.Color{R: 255, G: 64, B: 64}
+)
+
+// detailLine is a line of text in a tooltip or the inspection panel
+type detailLine struct {
+	text  string
+	color sdl.Color
+}
+
+func itemTypeName(t game.ItemType) string {
+	switch t {
+	case game.Weapon:
+		return "Weapon"
+	case game.Armor:
+		return "Armor"
+	case game.Key:
+		return "Key"
+	case game.Ammo:
+		return "Ammunition"
+	case game.Gold:
+		return "Gold"
+	case game.Potion:
+		return "Potion"
+	default:
+		return "Item"
+	}
+}
+
+func itemName(item *game.Item) string {
+	if item.Count > 1 {
+		return item.Name + " x" + strconv.Itoa(item.Count)
+	}
+
+	return item.Name
+}
+
+// itemStats returns the lines describing what an item does
+func itemStats(item *game.Item) []detailLine {
+	lines := make([]detailLine, 0)
+
+	switch item.Type {
+	case game.Weapon:
+		lines = append(lines, detailLine{fmt.Sprintf("Damage x%.1f", item.Power), textColor})
+		if item.Range > 0 {
+			lines = append(lines, detailLine{"Range " + strconv.Itoa(item.Range), textColor})
+		}
+		if item.AmmoName != "" {
+			lines = append(lines, detailLine{"Uses " + item.AmmoName, textColor})
+		} else if item.Charges > 0 {
+			lines = append(lines, detailLine{"Charges " + strconv.Itoa(item.Charges), textColor})
+		}
+		if item.Thrown {
+			lines = append(lines, detailLine{"Can be thrown", textColor})
+		}
+	case game.Armor:
+		lines = append(lines, detailLine{fmt.Sprintf("Damage taken x%.1f", item.Power), textColor})
+	case game.Potion:
+		lines = append(lines, detailLine{fmt.Sprintf("Heals %.0f", item.Power), textColor})
+	}
+
+	return lines
+}
+
+// itemComparison returns how an item compares to what the player has equipped
+// in the same slot
+func itemComparison(item *game.Item, player *game.Player) []detailLine {
+	var equipped *game.Item
+	switch item.Type {
+	case game.Weapon:
+		equipped = player.Weapon
+	case game.Armor:
+		equipped = player.Armor
+	default:
+		return nil
+	}
+
+	if equipped == item {
+		return []detailLine{{"Equipped", textColor}}
+	}
+	if equipped == nil {
+		return []detailLine{{"Nothing equipped", textColor}}
+	}
+
+	lines := []detailLine{{"Compared to " + equipped.Name + ":", textColor}}
+	compare := func(format string, diff float64, higherIsBetter bool) {
+		if diff == 0 {
+			return
+		}
+
+		color := betterColor
+		if (diff > 0) != higherIsBetter {
+			color = worseColor
+		}
+		lines = append(lines, detailLine{fmt.Sprintf(format, diff), color})
+	}
+
+	if item.Type == game.Weapon {
+		compare("  Damage %+.1f", item.Power-equipped.Power, true)
+		compare("  Range %+.0f", float64(item.Range-equipped.Range), true)
+	} else {
+		compare("  Damage taken %+.1f", item.Power-equipped.Power, false)
+	}
+	compare("  Weight %+.1f", item.Weight-equipped.Weight, false)
+
+	if len(lines) == 1 {
+		lines = append(lines, detailLine{"  No difference", textColor})
+	}
+
+	return lines
+}
+
+// hoveredItem returns the item under the mouse in the current screen
+func (a *App) hoveredItem(mx int32, my int32) *game.Item {
+	switch a.state {
+	case mainState:
+		return a.checkForFloorItem(mx, my)
+	case inventoryState:
+		if item := a.checkForInventoryItem(mx, my); item != nil {
+			return item
+		}
+		return a.checkForEquippedItem(mx, my)
+	case tradeState:
+		item, _ := a.checkForTradeItem(mx, my)
+		return item
+	}
+
+	return nil
+}
+
+// drawHoveredTooltip draws a short description of the item under the mouse
+func (a *App) drawHoveredTooltip() {
+	if a.dragged != nil {
+		return
+	}
+
+	mx, my, _ := sdl.GetMouseState()
+	item := a.hoveredItem(mx, my)
+	if item == nil {
+		return
+	}
+
+	lines := []detailLine{
+		{itemName(item), textColor},
+		{itemTypeName(item.Type), textColor},
+	}
+	lines = append(lines, itemStats(item)...)
+	lines = append(lines, itemComparison(item, a.loadedLevel.Player)...)
+
+	a.drawTooltip(lines, mx, my)
+}
+
+// drawTooltip draws lines of text in a box next to the mouse
+func (a *App) drawTooltip(lines []detailLine, mx int32, my int32) {
+	textures := make([]*sdl.Texture, 0)
+	width, height := int32(0), int32(0)
+	for _, line := range lines {
+		tex := a.stringToTexture(line.text, smallFont, line.color)
+		_, _, w, h, err := tex.Query()
+		if err != nil {
+			continue
+		}
+		textures = append(textures, tex)
+		if w > width {
+			width = w
+		}
+		height += h
+	}
+
+	// keep the tooltip on screen
+	x, y := mx+16, my+16
+	if x+width+8 > a.width {
+		x = mx - width - 24
+	}
+	if y+height+8 > a.height {
+		y = my - height - 24
+	}
+
+	a.copy(a.slotBackground, nil, &sdl.Rect{X: x, Y: y, W: width + 8, H: height + 8})
+
+	y += 4
+	for _, tex := range textures {
+		_, _, w, h, _ := tex.Query()
+		a.copy(tex, nil, &sdl.Rect{X: x + 4, Y: y, W: w, H: h})
+		y += h
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/chumnend/dungeon-rpg/internal/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	merchantSide = iota
+	playerSide
+)
+
+// checkForTradeItem returns the item under the mouse in the trade screen and
+// which side of the trade it is on
+func (a *App) checkForTradeItem(mx int32, my int32) (*game.Item, int) {
+	mouseRect := a.getMouseRect(mx, my)
+
+	level := a.loadedLevel
+	if level.Trading == nil {
+		return nil, merchantSide
+	}
+
+	for i, item := range level.Trading.Items {
+		if a.getTradeRowRect(merchantSide, i).HasIntersection(mouseRect) {
+			return item, merchantSide
+		}
+	}
+
+	for i, item := range level.Player.Items {
+		if a.getTradeRowRect(playerSide, i).HasIntersection(mouseRect) {
+			return item, playerSide
+		}
+	}
+
+	return nil, merchantSide
+}
+
+func (a *App) drawTrade() {
+	merchant := a.loadedLevel.Trading
+	player := a.loadedLevel.Player
+	if merchant == nil {
+		return
+	}
+
+	a.copy(a.inventoryBackground, nil, a.getInventoryBackdropRect())
+
+	white := sdl.Color{R: 255, G: 255, B: 255}
+	a.drawTradeText(merchant.Name+"'s wares ("+strconv.Itoa(merchant.Gold)+" gold)", merchantSide, white)
+	a.drawTradeText("Your items ("+strconv.Itoa(player.Gold)+" gold)", playerSide, white)
+
+	for i, item := range merchant.Items {
+		color := white
+		if item.Price() > player.Gold {
+			color = sdl.Color{R: 128, G: 128, B: 128}
+		}
+		a.drawTradeRow(item, item.Price(), merchantSide, i, color)
+	}
+
+	for i, item := range player.Items {
+		color := white
+		if item.SellPrice() <= 0 || item.SellPrice() > merchant.Gold {
+			color = sdl.Color{R: 128, G: 128, B: 128}
+		}
+		a.drawTradeRow(item, item.SellPrice(), playerSide, i, color)
+	}
+}
+
+func (a *App) drawTradeText(s string, side int, color sdl.Color) {
+	headerRect := a.getTradeRowRect(side, -1)
+
+	tex := a.stringToTexture(s, smallFont, color)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: headerRect.X, Y: headerRect.Y, W: w, H: h})
+	}
+}
+
+func (a *App) drawTradeRow(item *game.Item, price int, side int, i int, color sdl.Color) {
+	rowRect := a.getTradeRowRect(side, i)
+
+	if srcRects, exists := a.textureIndex[item.Symbol]; exists {
+		a.copy(a.textureAtlas, &srcRects[0], &sdl.Rect{X: rowRect.X, Y: rowRect.Y, W: rowRect.H, H: rowRect.H})
+	}
+
+	tex := a.stringToTexture(itemName(item)+" - "+strconv.Itoa(price)+" gold", smallFont, color)
+	_, _, w, h, err := tex.Query()
+	if err == nil {
+		a.copy(tex, nil, &sdl.Rect{X: rowRect.X + rowRect.H + 4, Y: rowRect.Y + (rowRect.H-h)/2, W: w, H: h})
+	}
+}
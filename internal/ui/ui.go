@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/chumnend/dungeon-rpg/internal/game"
 	"github.com/veandco/go-sdl2/mix"
@@ -38,65 +39,111 @@ type App struct {
 	centerX int
 	centerY int
 
-	state       appState
-	r           *rand.Rand
-	game        *game.Game
-	loadedLevel *game.Level
-	dragged     *game.Item
+	cameraX     float64
+	cameraY     float64
+	cameraLevel *game.Level
+	cameraMoved time.Time
+	zoom        int
+	mapX        int
+	mapY        int
+	mapZoom     int
+
+	state           appState
+	r               *rand.Rand
+	game            *game.Game
+	loadedLevel     *game.Level
+	inputQueue      []*game.Input
+	turnPending     bool
+	dragged         *game.Item
+	inventoryScroll int
+	inspected       *game.Item
+	inventoryCursor int
+	inventoryAction inventoryAction
+	editor          levelEditor
+	target          game.Pos
+	casting         int
+	bindings        map[sdl.Scancode]keyAction
+	padBindings     map[uint8]keyAction
+	controllers     map[sdl.JoystickID]*sdl.GameController
+	stickX          int
+	stickY          int
+	choiceCursor    int
 
 	window       *sdl.Window
 	renderer     *sdl.Renderer
 	textureAtlas *sdl.Texture
 	textureIndex map[rune][]sdl.Rect
+	frameIndex   map[rune][]sdl.Rect
+
+	floorChunks    [][]*floorChunk
+	floorLevel     *game.Level
+	floorTiles     [][]floorTile
+	floorDebug     map[game.Pos]bool
+	floorLight     int
+	tileVariations map[*game.Level][][]int
+
+	sprites       map[*game.Character]*spriteAnimation
+	animatedLevel *game.Level
+	floatingTexts []floatingText
+	particles     []particle
+	damageFlash   time.Time
+	shake         time.Time
+	shakeX        int32
+	shakeY        int32
+	logScroll     int
+	logHidden     map[game.MessageCategory]bool
+	messages      []*game.LogEntry
+
+	vsync      bool
+	maxFPS     int
+	dirty      bool
+	drawnFrame int64
+	showStats  bool
+	stats      frameStats
 
 	eventBackground     *sdl.Texture
 	inventoryBackground *sdl.Texture
 	slotBackground      *sdl.Texture
+	portalHighlight     *sdl.Texture
+	targetHighlight     *sdl.Texture
 
-	str2TexSmall  map[string]*sdl.Texture
-	str2TexMedium map[string]*sdl.Texture
-	str2TexLarge  map[string]*sdl.Texture
-	smallFont     *ttf.Font
-	mediumFont    *ttf.Font
-	largeFont     *ttf.Font
+	textCache    *textCache
+	glyphAtlases map[fontSize]*glyphAtlas
+	smallFont    *ttf.Font
+	mediumFont   *ttf.Font
+	largeFont    *ttf.Font
 
+	music          map[string]*mix.Music
+	currentMusic   string
 	footstepSounds []*mix.Chunk
 	doorOpenSounds []*mix.Chunk
 }
 
-// NewApp returns an App struct
-func NewApp(game *game.Game, width, height int32) *App {
-	window, err := sdl.CreateWindow("RPG", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN)
+// NewApp returns an App struct. With vsync frames are presented in step with
+// the display, maxFPS caps how many are drawn a second, 0 for no cap.
+func NewApp(game *game.Game, width, height int32, vsync bool, maxFPS int) *App {
+	window, err := sdl.CreateWindow("RPG", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	if err != nil {
 		panic(err)
 	}
 
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
-	if err != nil {
-		panic(err)
-	}
-
-	// sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "1")
-
-	r := rand.New(rand.NewSource(1))
+	window.SetMinimumSize(minWindowWidth, minWindowHeight)
 
-	smallFont, err := ttf.OpenFont("internal/ui/assets/fonts/Kingthings.ttf", int(float64(width)*0.015))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open font: %s\n", err)
-		panic(err)
+	var rendererFlags uint32 = sdl.RENDERER_ACCELERATED
+	if vsync {
+		rendererFlags |= sdl.RENDERER_PRESENTVSYNC
 	}
 
-	mediumFont, err := ttf.OpenFont("internal/ui/assets/fonts/Kingthings.ttf", int(float64(width)*0.025))
+	renderer, err := sdl.CreateRenderer(window, -1, rendererFlags)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open font: %s\n", err)
 		panic(err)
 	}
 
-	largeFont, err := ttf.OpenFont("internal/ui/assets/fonts/Kingthings.ttf", int(float64(width)*0.05))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open font: %s\n", err)
-		panic(err)
-	}
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+
+	// sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "1")
+
+	r := rand.New(rand.NewSource(1))
 
 	err = mix.OpenAudio(22050, mix.DEFAULT_FORMAT, 2, 4096)
 	if err != nil {
@@ -104,13 +151,6 @@ func NewApp(game *game.Game, width, height int32) *App {
 		panic(err)
 	}
 
-	music, err := mix.LoadMUS("internal/ui/assets/sound/ambient.ogg")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to play music: %s\n", err)
-		panic(err)
-	}
-	music.Play(-1)
-
 	footstepSounds := make([]*mix.Chunk, 0)
 	footstepBase := "internal/ui/assets/sound/footstep0"
 	for i := 0; i < 6; i++ {
@@ -140,27 +180,32 @@ func NewApp(game *game.Game, width, height int32) *App {
 	a := &App{
 		width:          width,
 		height:         height,
-		centerX:        -1,
-		centerY:        -1,
+		zoom:           defaultZoom,
+		mapZoom:        defaultMapZoom,
 		state:          mainState,
 		r:              r,
 		game:           game,
 		loadedLevel:    nil,
 		dragged:        nil,
+		casting:        -1,
+		vsync:          vsync,
+		maxFPS:         maxFPS,
+		dirty:          true,
+		controllers:    make(map[sdl.JoystickID]*sdl.GameController),
 		window:         window,
 		renderer:       renderer,
-		str2TexSmall:   make(map[string]*sdl.Texture),
-		str2TexMedium:  make(map[string]*sdl.Texture),
-		str2TexLarge:   make(map[string]*sdl.Texture),
-		smallFont:      smallFont,
-		mediumFont:     mediumFont,
-		largeFont:      largeFont,
+		textCache:      newTextCache(maxCachedTexts),
+		glyphAtlases:   make(map[fontSize]*glyphAtlas),
+		music:          make(map[string]*mix.Music),
 		footstepSounds: footstepSounds,
 		doorOpenSounds: doorOpenSounds,
 	}
 
+	a.loadFonts()
+	a.bindings, a.padBindings = loadKeyBindings(keysFile)
+
 	a.textureAtlas = a.imgFileToTexture("internal/ui/assets/tiles/tiles.png")
-	a.textureIndex = a.loadTextureIndex("internal/ui/assets/atlas-index.txt")
+	a.textureIndex, a.frameIndex = a.loadTextureIndex("internal/ui/assets/atlas-index.txt")
 
 	a.eventBackground = a.getSinglePixelTexture(sdl.Color{R: 0, G: 0, B: 0, A: 128})
 	a.eventBackground.SetBlendMode(sdl.BLENDMODE_BLEND)
@@ -170,24 +215,34 @@ func NewApp(game *game.Game, width, height int32) *App {
 
 	a.slotBackground = a.getSinglePixelTexture(sdl.Color{R: 0, G: 0, B: 0, A: 255})
 
+	a.portalHighlight = a.getSinglePixelTexture(sdl.Color{R: 0, G: 64, B: 255, A: 96})
+
+	a.targetHighlight = a.getSinglePixelTexture(sdl.Color{R: 255, G: 0, B: 0, A: 96})
+
 	return a
 }
 
 // Start starts the application window
 func (a *App) Start() {
 
-	// run the game engine
+	// run the game engine, which hands over the level it starts on
 	go a.game.Run()
+	a.updateLevel(<-a.game.LevelCh)
 
+	nextFrame := time.Now()
 	for {
 		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			a.dirty = true
+
+			// anything that looks at the level waits for the game to
+			// hand it back first
+			if a.readsLevel(event) {
+				a.awaitTurn()
+			}
+
 			switch e := event.(type) {
 			case *sdl.QuitEvent:
-				input := game.Input{
-					Type: game.QuitGame,
-				}
-
-				a.game.InputCh <- &input
+				a.quit()
 				return
 
 			// check mouse events
@@ -198,12 +253,14 @@ func (a *App) Start() {
 						Type: game.None,
 					}
 
-					if e.Type == sdl.MOUSEBUTTONUP {
+					if e.Type == sdl.MOUSEBUTTONUP && e.Button == sdl.BUTTON_RIGHT {
+						a.inspect(e.X, e.Y)
+					} else if e.Type == sdl.MOUSEBUTTONUP {
 						item := a.checkForFloorItem(e.X, e.Y)
 						if item != nil {
 							input.Type = game.TakeItem
 							input.Item = item
-							a.game.InputCh <- &input
+							a.queueInput(&input)
 						}
 					}
 
@@ -212,7 +269,12 @@ func (a *App) Start() {
 						Type: game.None,
 					}
 
-					if e.Type == sdl.MOUSEBUTTONDOWN {
+					if e.Type == sdl.MOUSEBUTTONUP && e.Button == sdl.BUTTON_RIGHT {
+						a.inspect(e.X, e.Y)
+						break
+					}
+
+					if e.Type == sdl.MOUSEBUTTONDOWN && e.Button == sdl.BUTTON_LEFT {
 						// look for drag event if in inventory
 						item := a.checkForInventoryItem(e.X, e.Y)
 						if item != nil {
@@ -226,92 +288,240 @@ func (a *App) Start() {
 							if item != nil {
 								input.Type = game.EquipItem
 								input.Item = item
-								a.game.InputCh <- &input
+								a.queueInput(&input)
 							}
 
 							shouldDrop := a.checkForDropItem(e.X, e.Y)
 							if shouldDrop {
 								input.Type = game.DropItem
 								input.Item = a.dragged
-								a.game.InputCh <- &input
+								a.queueInput(&input)
 							}
 
 							a.dragged = nil
 						}
 					}
-				}
 
-			// check keyboard events
-			case *sdl.KeyboardEvent:
-				switch a.state {
-				case mainState:
-					input := game.Input{
-						Type: game.None,
-					}
+				case tradeState:
+					if e.Type == sdl.MOUSEBUTTONUP && e.Button == sdl.BUTTON_RIGHT {
+						a.inspect(e.X, e.Y)
+					} else if e.Type == sdl.MOUSEBUTTONUP {
+						item, side := a.checkForTradeItem(e.X, e.Y)
+						if item != nil {
+							input := game.Input{
+								Type: game.Buy,
+								Item: item,
+							}
+							if side == playerSide {
+								input.Type = game.Sell
+							}
 
-					if e.Type == sdl.KEYUP {
-						switch e.Keysym.Scancode {
-						case sdl.SCANCODE_UP:
-							input.Type = game.Up
-						case sdl.SCANCODE_DOWN:
-							input.Type = game.Down
-						case sdl.SCANCODE_LEFT:
-							input.Type = game.Left
-						case sdl.SCANCODE_RIGHT:
-							input.Type = game.Right
-						case sdl.SCANCODE_I:
-							a.toggleInventory()
-						case sdl.SCANCODE_T:
-							input.Type = game.TakeAll
-						default:
-							// do nothing
+							a.queueInput(&input)
 						}
-
-						a.game.InputCh <- &input
 					}
 
-				case inventoryState:
-					input := game.Input{
-						Type: game.None,
+				case dialogueState:
+					if e.Type == sdl.MOUSEBUTTONUP {
+						choice := a.checkForChoice(e.X, e.Y)
+						if choice != -1 {
+							a.choiceCursor = 0
+							input := game.Input{
+								Type:   game.Choose,
+								Choice: choice,
+							}
+
+							a.queueInput(&input)
+						}
 					}
 
-					if e.Type == sdl.KEYUP {
-						switch e.Keysym.Scancode {
-						case sdl.SCANCODE_I:
-							a.toggleInventory()
-						default:
-							// do nothing
+				case editorState:
+					if e.Type == sdl.MOUSEBUTTONDOWN {
+						slot := a.checkForPaletteSlot(e.X, e.Y)
+						if slot != -1 {
+							a.editor.selected = slot
+						} else if e.Button == sdl.BUTTON_LEFT {
+							a.startStroke(e.X, e.Y, false)
+						} else if e.Button == sdl.BUTTON_RIGHT {
+							a.startStroke(e.X, e.Y, true)
 						}
+					}
 
-						a.game.InputCh <- &input
+					if e.Type == sdl.MOUSEBUTTONUP {
+						a.endStroke()
 					}
 				}
-			}
-		}
 
-		// check for level update
-		select {
-		case loadedLevel, ok := <-a.game.LevelCh:
-			if ok {
-				a.loadedLevel = loadedLevel // keep track of the loaded level
-
-				switch loadedLevel.LastEvent {
-				case game.Move:
-					playRandomSound(a.footstepSounds, 64)
-				case game.DoorOpen:
-					playRandomSound(a.doorOpenSounds, 64)
-				default:
-					// do nothing
+			case *sdl.RenderEvent:
+				// redraw the cached floor, recreating it if its
+				// textures were lost with the device
+				if e.Type == sdl.RENDER_DEVICE_RESET {
+					a.resetFloor()
+				} else {
+					a.invalidateFloor()
+				}
+
+			case *sdl.WindowEvent:
+				if e.Event == sdl.WINDOWEVENT_SIZE_CHANGED {
+					a.resize(e.Data1, e.Data2)
+				}
+
+			case *sdl.MouseWheelEvent:
+				switch a.state {
+				case inventoryState:
+					a.scrollInventory(-int(e.Y))
+				case mainState, editorState:
+					a.zoomCamera(int(e.Y))
+				case mapState:
+					a.zoomMap(int(e.Y))
+				case logState:
+					a.scrollLog(int(e.Y))
+				}
+
+			case *sdl.MouseMotionEvent:
+				if a.state == editorState {
+					a.applyStroke(e.X, e.Y)
+				}
+
+			// check keyboard events
+			case *sdl.KeyboardEvent:
+				a.handleKey(e)
+
+			// check gamepad events
+			case *sdl.ControllerDeviceEvent:
+				if e.Type == sdl.CONTROLLERDEVICEADDED {
+					a.addController(int(e.Which))
+				} else if e.Type == sdl.CONTROLLERDEVICEREMOVED {
+					a.removeController(e.Which)
 				}
+
+			case *sdl.ControllerButtonEvent:
+				if e.Type == sdl.CONTROLLERBUTTONDOWN {
+					a.handleButton(e.Button)
+				}
+
+			case *sdl.ControllerAxisEvent:
+				a.handleAxis(e)
 			}
-		default:
-			// do nothing
 		}
 
-		// draw to the window
-		a.draw()
+		// take the level back if the game has finished its turn and draw
+		// it before handing it over for the next queued one, the game
+		// takes its turn while the loop waits for the next frame. Only a
+		// turn longer than a frame leaves the last frame on screen.
+		a.pollTurn()
+		drew := false
+		if !a.turnPending && a.needsRedraw() {
+			a.draw()
+			drew = true
+		}
+		a.sendQueuedInput()
+
+		nextFrame = a.waitForFrame(nextFrame, drew)
+	}
+}
+
+// needsRedraw reports whether anything on screen could have changed since the
+// last frame was drawn
+func (a *App) needsRedraw() bool {
+	frame := getAnimationFrame()
+	animated := len(a.frameIndex) > 0 && frame != a.drawnFrame
+	a.drawnFrame = frame
+
+	stats := a.updateStats()
+
+	return a.dirty || animated || stats || a.isAnimating() || a.hasEffects() || a.isCameraMoving()
+}
+
+// waitForFrame sleeps until the next frame is due and returns when the one
+// after is. With an fps cap frames are drawn on a fixed timestep, dropping
+// any that fall behind rather than rushing to catch up. Without one the loop
+// only pauses when there was nothing to draw, vsync paces the rest.
+func (a *App) waitForFrame(next time.Time, drew bool) time.Time {
+	now := time.Now()
+	if a.maxFPS <= 0 {
+		if !drew {
+			sdl.Delay(1)
+		}
+		return now
+	}
+
+	step := time.Second / time.Duration(a.maxFPS)
+	next = next.Add(step)
+	if now.Sub(next) > step {
+		next = now
+	}
+
+	if wait := next.Sub(now); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return next
+}
+
+// updateLevel takes back the level after a turn and reacts to what happened
+func (a *App) updateLevel(loadedLevel *game.Level) {
+	a.loadedLevel = loadedLevel // keep track of the loaded level
+	a.updateFloor(loadedLevel)
+	a.clampInventoryCursor()
+	a.animateLevel(loadedLevel)
+	a.addCombatEffects(loadedLevel)
+	a.playMusic(loadedLevel.Music)
+
+	// open and close the dialogue and trade screens as the game
+	// starts and ends conversations and trades
+	if a.state == mainState && loadedLevel.Talking != nil {
+		a.state = dialogueState
+	} else if a.state == dialogueState && loadedLevel.Talking == nil {
+		a.state = mainState
+	}
+
+	if a.state == mainState && loadedLevel.Trading != nil {
+		a.state = tradeState
+	} else if a.state == tradeState && loadedLevel.Trading == nil {
+		a.state = mainState
+	}
 
-		sdl.Delay(10)
+	a.playEventSounds(loadedLevel)
+}
+
+func (a *App) playMusic(name string) {
+	if name == a.currentMusic {
+		return
+	}
+
+	music, exists := a.music[name]
+	if !exists {
+		var err error
+		music, err = mix.LoadMUS("internal/ui/assets/sound/" + name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to play music: %s\n", err)
+			panic(err)
+		}
+		a.music[name] = music
+	}
+
+	music.Play(-1)
+	a.currentMusic = name
+}
+
+// playEventSounds plays the sounds for the last turn, once each however many
+// times they happened
+func (a *App) playEventSounds(level *game.Level) {
+	footstep, door := false, false
+	for _, event := range level.Events {
+		switch event.Type {
+		case game.Moved:
+			footstep = footstep || event.Actor == &level.Player.Character
+		case game.DoorOpened, game.DoorClosed:
+			door = true
+		}
+	}
+
+	if footstep {
+		playRandomSound(a.footstepSounds, 64)
+	}
+	if door {
+		playRandomSound(a.doorOpenSounds, 64)
 	}
 }
 
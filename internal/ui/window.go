@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+const fontFile = "internal/ui/assets/fonts/Kingthings.ttf"
+
+// the smallest window the HUD and inventory still fit in
+const (
+	minWindowWidth  = 640
+	minWindowHeight = 365
+)
+
+// openFont opens the game font at a size relative to the window width
+func (a *App) openFont(ratio float64) *ttf.Font {
+	font, err := ttf.OpenFont(fontFile, int(float64(a.width)*ratio))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open font: %s\n", err)
+		panic(err)
+	}
+
+	return font
+}
+
+// loadFonts opens the fonts sized for the current window, replacing any
+// already open along with the text rendered in them
+func (a *App) loadFonts() {
+	a.clearTextCache()
+
+	for _, font := range []*ttf.Font{a.smallFont, a.mediumFont, a.largeFont} {
+		if font != nil {
+			font.Close()
+		}
+	}
+
+	a.smallFont = a.openFont(0.015)
+	a.mediumFont = a.openFont(0.025)
+	a.largeFont = a.openFont(0.05)
+}
+
+// clearTextCache destroys every cached text texture and glyph atlas
+func (a *App) clearTextCache() {
+	a.textCache.clear()
+
+	for size, atlas := range a.glyphAtlases {
+		atlas.texture.Destroy()
+		delete(a.glyphAtlases, size)
+	}
+}
+
+// resize lays the screen out again for a new window size
+func (a *App) resize(width int32, height int32) {
+	if width == a.width && height == a.height {
+		return
+	}
+
+	a.width = width
+	a.height = height
+	a.loadFonts()
+
+	// the inventory grid may have gained or lost columns
+	if a.loadedLevel != nil {
+		a.setInventoryCursor(a.inventoryCursor)
+		a.scrollInventory(0)
+	}
+}
+
+// toggleFullscreen switches between windowed and fullscreen at the desktop
+// resolution, the resize event that follows lays the screen out again
+func (a *App) toggleFullscreen() {
+	var flags uint32
+	if a.window.GetFlags()&sdl.WINDOW_FULLSCREEN_DESKTOP != sdl.WINDOW_FULLSCREEN_DESKTOP {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+
+	err := a.window.SetFullscreen(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to toggle fullscreen: %s\n", err)
+	}
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"flag"
+
 	"github.com/chumnend/dungeon-rpg/internal/game"
 	"github.com/chumnend/dungeon-rpg/internal/ui"
 )
 
 func main() {
+	vsync := flag.Bool("vsync", true, "present frames in step with the display")
+	maxFPS := flag.Int("fps", 60, "most frames drawn a second, 0 for no limit")
+	flag.Parse()
+
 	// setup app
 	game := game.NewGame("internal/game/maps/level1.map")
-	app := ui.NewApp(game, 1280, 730)
+	app := ui.NewApp(game, 1280, 730, *vsync, *maxFPS)
 
 	// start the app
 	app.Start()